@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+	flag "github.com/spf13/pflag"
+)
+
+// defaultIgnoreFile is read from the input directory when it exists
+const defaultIgnoreFile = ".mdwikiignore"
+
+// ignoreRule is one gitignore-style line of an ignore file
+type ignoreRule struct {
+	pattern string
+	negate  bool // "!pattern" re-includes paths an earlier rule ignored
+	dirOnly bool // "pattern/" only matches directories
+}
+
+// pathMatcher decides which files under the input directory are converted.
+// Paths are slash-separated and relative to the input directory.
+type pathMatcher struct {
+	include []string
+	exclude []string
+	ignore  []ignoreRule
+}
+
+// matchGlob matches a relative path against a glob. Patterns without a
+// slash match the base name at any depth; others match the whole path,
+// with "**" standing for any number of folders.
+func matchGlob(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, err := path.Match(pattern, path.Base(rel))
+		return err == nil && ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// included reports whether a file matches the include globs
+func (m *pathMatcher) included(rel string) bool {
+	for _, pattern := range m.include {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// excluded reports whether a file or directory is excluded by a glob or
+// the ignore file, where the last matching ignore rule wins
+func (m *pathMatcher) excluded(rel string, isDir bool) bool {
+	for _, pattern := range m.exclude {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	ignored := false
+	for _, rule := range m.ignore {
+		if (!rule.dirOnly || isDir) && matchGlob(rule.pattern, rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// readIgnoreFile parses a gitignore-style file of glob patterns
+func readIgnoreFile(name string) ([]ignoreRule, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// batchResult is the outcome of converting one file
+type batchResult struct {
+	rel         string
+	diagnostics []converter.Diagnostic
+	err         error
+}
+
+// collectFiles walks the input directory and returns the files to convert
+// and the number of included files that were excluded. Hidden files and
+// folders, such as Obsidian's .obsidian settings, are never visited.
+func collectFiles(inputDir string, matcher *pathMatcher) (files []string, skipped int, err error) {
+	err = filepath.WalkDir(inputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == inputDir {
+			return nil
+		}
+		rel, err := filepath.Rel(inputDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if matcher.excluded(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matcher.included(rel) {
+			return nil
+		}
+		if matcher.excluded(rel, false) {
+			skipped++
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, skipped, err
+}
+
+// outputPath mirrors a relative input path under the output directory
+// with a .txt extension
+func outputPath(outputDir, rel string) string {
+	rel = filepath.FromSlash(rel)
+	return filepath.Join(outputDir, strings.TrimSuffix(rel, filepath.Ext(rel))+".txt")
+}
+
+// convertPath converts one Markdown file and writes the result, creating
+// the output folder, and returns the conversion diagnostics. A panic in the
+// converter is reported as an error so a batch or watch session carries on
+// with the other files.
+func convertPath(input, output string, config converter.Config) (diagnostics []converter.Diagnostic, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("conversion failed: %v", r)
+		}
+	}()
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, err
+	}
+	result := converter.Convert(string(data), config)
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return result.Diagnostics, err
+	}
+	return result.Diagnostics, os.WriteFile(output, []byte(result.Text), 0644)
+}
+
+// convertFiles converts files with a pool of workers and returns the
+// results with failures or diagnostics, sorted by path
+func convertFiles(inputDir, outputDir string, files []string, config converter.Config, workers int) []batchResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan string)
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range jobs {
+				diagnostics, err := convertPath(filepath.Join(inputDir, filepath.FromSlash(rel)), outputPath(outputDir, rel), config)
+				results <- batchResult{rel: rel, diagnostics: diagnostics, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, rel := range files {
+			jobs <- rel
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var reports []batchResult
+	for result := range results {
+		if result.err != nil || len(result.diagnostics) > 0 {
+			reports = append(reports, result)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].rel < reports[j].rel })
+	return reports
+}
+
+// reportResults prints the diagnostics and errors of a batch and returns
+// the number of failed files. Files with error diagnostics count as failed,
+// and with strict set so do files with warnings.
+func reportResults(inputDir string, reports []batchResult, strict bool, logf func(format string, args ...interface{})) int {
+	failed := 0
+	for _, report := range reports {
+		printDiagnostics(os.Stderr, filepath.Join(inputDir, filepath.FromSlash(report.rel)), report.diagnostics)
+		if report.err != nil {
+			logf("❌ %s: %v", report.rel, report.err)
+			failed++
+		} else if failure := diagnosticFailure(report.diagnostics, strict); failure != "" {
+			logf("❌ %s: %s", report.rel, failure)
+			failed++
+		}
+	}
+	return failed
+}
+
+// runConvert implements the convert subcommand, which converts every
+// Markdown file under a directory. It returns the process exit code.
+func runConvert(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	var (
+		inputDir   string
+		outputDir  string
+		include    []string
+		exclude    []string
+		ignoreFile string
+		workers    int
+		watch      bool
+		interval   time.Duration
+		options    conversionOptions
+	)
+	flags.StringVar(&inputDir, "input-dir", "", "Directory (e.g. an Obsidian vault) to convert")
+	flags.StringVar(&outputDir, "output-dir", "", "Directory for the .txt outputs, mirroring the input folders")
+	flags.StringSliceVar(&include, "include", []string{"*.md"}, "Glob of files to convert (repeatable; ** matches any folders)")
+	flags.StringSliceVar(&exclude, "exclude", nil, "Glob of files or folders to skip (repeatable)")
+	flags.StringVar(&ignoreFile, "ignore-file", "", "Gitignore-style file of paths to skip (default: "+defaultIgnoreFile+" in the input directory)")
+	flags.IntVarP(&workers, "jobs", "j", runtime.NumCPU(), "Number of files converted in parallel")
+	flags.BoolVarP(&watch, "watch", "w", false, "Keep running and re-convert files when they change")
+	flags.DurationVar(&interval, "watch-interval", 500*time.Millisecond, "How often --watch checks for changes")
+	options.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	if inputDir == "" || outputDir == "" {
+		fmt.Fprintln(os.Stderr, "Error: convert needs --input-dir and --output-dir")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Usage: md-to-mediawiki-go convert --input-dir <vault> --output-dir <out> [options]")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	config, err := options.config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	matcher := &pathMatcher{include: include, exclude: exclude}
+	if ignoreFile == "" {
+		if candidate := filepath.Join(inputDir, defaultIgnoreFile); isFile(candidate) {
+			ignoreFile = candidate
+		}
+	}
+	if ignoreFile != "" {
+		if matcher.ignore, err = readIgnoreFile(ignoreFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading ignore file '%s': %v\n", ignoreFile, err)
+			return 1
+		}
+	}
+
+	files, skipped, err := collectFiles(inputDir, matcher)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading directory '%s': %v\n", inputDir, err)
+		return 1
+	}
+
+	reports := convertFiles(inputDir, outputDir, files, config, workers)
+	failed := reportResults(inputDir, reports, options.strict, func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	})
+
+	converted := len(files) - failed
+	fmt.Printf("Converted %d, skipped %d, failed %d ('%s' -> '%s')\n", converted, skipped, failed, inputDir, outputDir)
+	if watch {
+		watchDir(inputDir, outputDir, matcher, config, workers, interval, options.strict)
+		return 0
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
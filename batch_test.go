@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		input    string
+		expected bool
+	}{
+		{name: "Base Name At Any Depth", pattern: "*.md", input: "notes/daily/today.md", expected: true},
+		{name: "Base Name Mismatch", pattern: "*.md", input: "notes/image.png", expected: false},
+		{name: "Anchored Path", pattern: "notes/*.md", input: "notes/a.md", expected: true},
+		{name: "Anchored Path Too Deep", pattern: "notes/*.md", input: "notes/daily/a.md", expected: false},
+		{name: "Globstar", pattern: "notes/**/*.md", input: "notes/daily/2024/a.md", expected: true},
+		{name: "Globstar Matches No Folders", pattern: "notes/**/*.md", input: "notes/a.md", expected: true},
+		{name: "Leading Slash", pattern: "/templates", input: "templates", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchGlob(tt.pattern, tt.input)
+			if got != tt.expected {
+				t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"index.md",
+		"notes/a.md",
+		"notes/image.png",
+		"notes/draft-b.md",
+		"templates/t.md",
+		"archive/old.md",
+		"archive/keep.md",
+		".obsidian/workspace.md",
+	} {
+		writeTestFile(t, filepath.Join(dir, name), "# Title\n")
+	}
+	writeTestFile(t, filepath.Join(dir, ".mdwikiignore"), "# comment\ntemplates/\narchive/*\n!archive/keep.md\n")
+
+	rules, err := readIgnoreFile(filepath.Join(dir, ".mdwikiignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := &pathMatcher{include: []string{"*.md"}, exclude: []string{"draft-*"}, ignore: rules}
+
+	files, skipped, err := collectFiles(dir, matcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"archive/keep.md", "index.md", "notes/a.md"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("collectFiles() = %v, want %v", files, expected)
+	}
+	if skipped != 2 {
+		t.Errorf("collectFiles() skipped = %d, want 2", skipped)
+	}
+}
+
+func TestConvertFiles(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+	writeTestFile(t, filepath.Join(inputDir, "a.md"), "# A\n")
+	writeTestFile(t, filepath.Join(inputDir, "sub/b.md"), "**B**\n")
+	writeTestFile(t, filepath.Join(inputDir, "bad/c.md"), "C\n")
+	writeTestFile(t, filepath.Join(inputDir, "warn.md"), "```\ncode\n")
+
+	// A file where the output folder should be makes c.md fail
+	writeTestFile(t, filepath.Join(outputDir, "bad"), "")
+
+	var options conversionOptions
+	config, err := options.config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports := convertFiles(inputDir, outputDir, []string{"a.md", "sub/b.md", "bad/c.md", "warn.md"}, config, 2)
+	if len(reports) != 2 || reports[0].rel != "bad/c.md" || reports[0].err == nil || reports[1].rel != "warn.md" {
+		t.Fatalf("convertFiles() reports = %v, want bad/c.md and warn.md", reports)
+	}
+	if d := reports[1].diagnostics; len(d) != 1 || d[0].Code != converter.CodeUnclosedFence {
+		t.Errorf("warn.md diagnostics = %v, want one %s", d, converter.CodeUnclosedFence)
+	}
+
+	logf := func(string, ...interface{}) {}
+	if failed := reportResults(inputDir, reports, false, logf); failed != 1 {
+		t.Errorf("reportResults() = %d, want 1", failed)
+	}
+	if failed := reportResults(inputDir, reports, true, logf); failed != 2 {
+		t.Errorf("reportResults() with strict = %d, want 2", failed)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outputDir, "sub", "b.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "'''B'''\n" {
+		t.Errorf("sub/b.txt = %q, want %q", got, "'''B'''\n")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+	flag "github.com/spf13/pflag"
+)
+
+// runCalloutTemplate implements the callout-template subcommand, which
+// writes the wiki template and TemplateStyles CSS for callouts converted
+// with --callout-template. It returns the process exit code.
+func runCalloutTemplate(args []string) int {
+	flags := flag.NewFlagSet("callout-template", flag.ContinueOnError)
+	var name, themeName, outputDir string
+	flags.StringVar(&name, "name", "Callout", "Template name, as passed to --callout-template")
+	flags.StringVar(&themeName, "theme", converter.ThemeTieto, "Callout colors and labels: 'tieto', 'plain' or a YAML/JSON theme file")
+	flags.StringVarP(&outputDir, "output-dir", "o", "", "Write <name>.wiki and <name>-styles.css to this folder (default: print both)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	theme, err := loadTheme(themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading theme: %v\n", err)
+		return 1
+	}
+	if theme == nil {
+		theme = converter.DefaultTheme()
+	}
+	template, css := theme.CalloutTemplate(name), theme.CalloutTemplateStyles()
+
+	if outputDir == "" {
+		fmt.Printf("<!-- Template:%s -->\n%s\n/* Template:%s/styles.css */\n%s", name, template, name, css)
+		return 0
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating '%s': %v\n", outputDir, err)
+		return 1
+	}
+	files := []struct{ path, content, page string }{
+		{filepath.Join(outputDir, name+".wiki"), template, "Template:" + name},
+		{filepath.Join(outputDir, name+"-styles.css"), css, "Template:" + name + "/styles.css"},
+	}
+	for _, file := range files {
+		if err := os.WriteFile(file.path, []byte(file.content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing file '%s': %v\n", file.path, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "✅ Wrote '%s' for the %s page\n", file.path, file.page)
+	}
+	return 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCalloutTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if code := runCalloutTemplate([]string{"--name", "Box", "-o", dir}); code != 0 {
+		t.Fatalf("callout-template exit code = %d, want 0", code)
+	}
+
+	template, err := os.ReadFile(filepath.Join(dir, "Box.wiki"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(template), `<templatestyles src="Box/styles.css" />`) {
+		t.Errorf("template = %q, want it to load Box/styles.css", template)
+	}
+	css, err := os.ReadFile(filepath.Join(dir, "Box-styles.css"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(css), ".callout-note {") {
+		t.Errorf("styles = %q, want the note callout colors", css)
+	}
+
+	if code := runCalloutTemplate([]string{"--theme", "missing.yaml"}); code != 1 {
+		t.Errorf("callout-template with a missing theme exit code = %d, want 1", code)
+	}
+}
@@ -0,0 +1,284 @@
+package converter
+
+// NodeKind identifies the Markdown construct a Node represents
+type NodeKind int
+
+// Block-level node kinds
+const (
+	DocumentNode NodeKind = iota
+	FrontMatterNode
+	ParagraphNode
+	HeadingNode
+	ThematicBreakNode
+	CodeBlockNode
+	HTMLBlockNode
+	BlockquoteNode
+	CalloutNode
+	FootnoteDefinitionNode
+	ListNode
+	ListItemNode
+	TableNode
+	TableRowNode
+	TableCellNode
+	DefinitionListNode
+	DefinitionTermNode
+	DefinitionNode
+	AttributionNode // "— Author" line closing a block quote
+
+	// Inline node kinds
+	TextNode
+	SoftBreakNode
+	HardBreakNode
+	CodeSpanNode
+	EmphasisNode
+	StrongNode
+	HighlightNode
+	StrikethroughNode
+	LinkNode
+	ImageNode
+	HTMLInlineNode
+	WikiLinkNode
+	EmbedNode
+	FootnoteRefNode
+	InlineFootnoteNode
+	WikitextNode // Wikitext written as is, for passes that insert markup
+)
+
+var nodeKindNames = map[NodeKind]string{
+	DocumentNode:           "Document",
+	FrontMatterNode:        "FrontMatter",
+	ParagraphNode:          "Paragraph",
+	HeadingNode:            "Heading",
+	ThematicBreakNode:      "ThematicBreak",
+	CodeBlockNode:          "CodeBlock",
+	HTMLBlockNode:          "HTMLBlock",
+	BlockquoteNode:         "Blockquote",
+	CalloutNode:            "Callout",
+	FootnoteDefinitionNode: "FootnoteDefinition",
+	ListNode:               "List",
+	ListItemNode:           "ListItem",
+	TableNode:              "Table",
+	TableRowNode:           "TableRow",
+	TableCellNode:          "TableCell",
+	DefinitionListNode:     "DefinitionList",
+	DefinitionTermNode:     "DefinitionTerm",
+	DefinitionNode:         "Definition",
+	AttributionNode:        "Attribution",
+	TextNode:               "Text",
+	SoftBreakNode:          "SoftBreak",
+	HardBreakNode:          "HardBreak",
+	CodeSpanNode:           "CodeSpan",
+	EmphasisNode:           "Emphasis",
+	StrongNode:             "Strong",
+	HighlightNode:          "Highlight",
+	StrikethroughNode:      "Strikethrough",
+	LinkNode:               "Link",
+	ImageNode:              "Image",
+	HTMLInlineNode:         "HTMLInline",
+	WikiLinkNode:           "WikiLink",
+	EmbedNode:              "Embed",
+	FootnoteRefNode:        "FootnoteRef",
+	InlineFootnoteNode:     "InlineFootnote",
+	WikitextNode:           "Wikitext",
+}
+
+// String returns the name of the node kind
+func (k NodeKind) String() string {
+	if name, ok := nodeKindNames[k]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// IsBlock reports whether nodes of this kind are block-level
+func (k NodeKind) IsBlock() bool {
+	return k < TextNode
+}
+
+// Node is an element of the Markdown document tree produced by Parse.
+// Children are kept in a doubly linked list so passes can insert, move and
+// remove nodes without rebuilding slices.
+type Node struct {
+	Kind NodeKind
+
+	Parent     *Node
+	FirstChild *Node
+	LastChild  *Node
+	Prev       *Node
+	Next       *Node
+
+	Literal string // Text, CodeSpan, CodeBlock, HTML and front matter content
+	Level   int    // Heading level (1-6)
+	Info    string // Fenced code block info string
+	Fenced  bool   // Code block was fenced rather than indented
+
+	Ordered bool // List is numbered
+	Start   int  // Start number of an ordered list
+	Tight   bool // List items are not separated by blank lines
+	Task    bool // List item is a task list item
+	Checked bool // Task list item is ticked
+
+	Destination string // Link, wikilink, embed or image target
+	Title       string // Link, image or callout title
+	Size        string // Embed or image size, e.g. "300" or "300x200"
+
+	CalloutType string // Callout type, e.g. "warning"
+	Fold        string // Callout fold marker: "+" (expanded), "-" (collapsed) or empty
+
+	Label string // Footnote label of a definition or reference
+
+	Header bool   // Table row is the header row
+	Align  string // Table cell alignment: "left", "center", "right" or empty
+
+	Line int // 1-based source line where the node starts (blocks only)
+
+	block *blockState // parser bookkeeping, nil once parsing is done
+}
+
+// NewNode creates a detached node of the given kind
+func NewNode(kind NodeKind) *Node {
+	return &Node{Kind: kind}
+}
+
+// newText creates a text node with the given content
+func newText(s string) *Node {
+	return &Node{Kind: TextNode, Literal: s}
+}
+
+// AppendChild adds child as the last child of n
+func (n *Node) AppendChild(child *Node) {
+	child.Unlink()
+	child.Parent = n
+	if n.LastChild != nil {
+		n.LastChild.Next = child
+		child.Prev = n.LastChild
+		n.LastChild = child
+	} else {
+		n.FirstChild = child
+		n.LastChild = child
+	}
+}
+
+// PrependChild adds child as the first child of n
+func (n *Node) PrependChild(child *Node) {
+	child.Unlink()
+	child.Parent = n
+	if n.FirstChild != nil {
+		n.FirstChild.Prev = child
+		child.Next = n.FirstChild
+		n.FirstChild = child
+	} else {
+		n.FirstChild = child
+		n.LastChild = child
+	}
+}
+
+// InsertAfter places sibling directly after n
+func (n *Node) InsertAfter(sibling *Node) {
+	sibling.Unlink()
+	sibling.Next = n.Next
+	if sibling.Next != nil {
+		sibling.Next.Prev = sibling
+	}
+	sibling.Prev = n
+	n.Next = sibling
+	sibling.Parent = n.Parent
+	if sibling.Parent != nil && sibling.Next == nil {
+		sibling.Parent.LastChild = sibling
+	}
+}
+
+// InsertBefore places sibling directly before n
+func (n *Node) InsertBefore(sibling *Node) {
+	sibling.Unlink()
+	sibling.Prev = n.Prev
+	if sibling.Prev != nil {
+		sibling.Prev.Next = sibling
+	}
+	sibling.Next = n
+	n.Prev = sibling
+	sibling.Parent = n.Parent
+	if sibling.Parent != nil && sibling.Prev == nil {
+		sibling.Parent.FirstChild = sibling
+	}
+}
+
+// Unlink detaches n from its parent and siblings
+func (n *Node) Unlink() {
+	if n.Prev != nil {
+		n.Prev.Next = n.Next
+	} else if n.Parent != nil {
+		n.Parent.FirstChild = n.Next
+	}
+	if n.Next != nil {
+		n.Next.Prev = n.Prev
+	} else if n.Parent != nil {
+		n.Parent.LastChild = n.Prev
+	}
+	n.Parent = nil
+	n.Next = nil
+	n.Prev = nil
+}
+
+// Children returns the direct children of n as a slice
+func (n *Node) Children() []*Node {
+	var children []*Node
+	for c := n.FirstChild; c != nil; c = c.Next {
+		children = append(children, c)
+	}
+	return children
+}
+
+// WalkStatus tells Walk how to continue after visiting a node
+type WalkStatus int
+
+const (
+	// WalkContinue descends into the node's children
+	WalkContinue WalkStatus = iota
+	// WalkSkipChildren moves on to the next sibling
+	WalkSkipChildren
+	// WalkStop ends the traversal
+	WalkStop
+)
+
+// Walk visits n and its descendants depth-first. The visitor is called with
+// entering=true before a node's children and entering=false after them.
+// The next sibling is captured before visiting, so the visitor may unlink
+// or replace the current node.
+func Walk(n *Node, visit func(node *Node, entering bool) WalkStatus) WalkStatus {
+	status := visit(n, true)
+	if status == WalkStop {
+		return WalkStop
+	}
+	if status == WalkContinue {
+		for c := n.FirstChild; c != nil; {
+			next := c.Next
+			if Walk(c, visit) == WalkStop {
+				return WalkStop
+			}
+			c = next
+		}
+	}
+	if visit(n, false) == WalkStop {
+		return WalkStop
+	}
+	return WalkContinue
+}
+
+// TextContent returns the concatenated plain text of n's inline descendants
+func (n *Node) TextContent() string {
+	var b []byte
+	Walk(n, func(node *Node, entering bool) WalkStatus {
+		if !entering {
+			return WalkContinue
+		}
+		switch node.Kind {
+		case TextNode, CodeSpanNode:
+			b = append(b, node.Literal...)
+		case SoftBreakNode, HardBreakNode:
+			b = append(b, ' ')
+		}
+		return WalkContinue
+	})
+	return string(b)
+}
@@ -0,0 +1,242 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CalloutConfig sets how callouts are written
+type CalloutConfig struct {
+	Template string // Write callouts as calls to this template, e.g. "Callout" for {{Callout|type=...}} (default: styled tables)
+}
+
+// MediaWiki extension tags whose content the preprocessor keeps whole, so
+// pipes inside them never separate template arguments
+var wikiExtensionTagRegex = regexp.MustCompile(`^<(nowiki|syntaxhighlight|source|pre|math|ref)\b[^>]*?(/?)>`)
+
+// renderCalloutTemplate renders a callout as a call to the callout template.
+// Paragraph-only content stays on one line; other blocks start on a line
+// of their own so lists and tables work.
+func (r *renderer) renderCalloutTemplate(n *Node) string {
+	args := []string{r.config.Callouts.Template, "type=" + n.CalloutType}
+	if n.Title != "" {
+		args = append(args, "title="+escapeTemplateArg(escapeText(n.Title)))
+	}
+	switch n.Fold {
+	case "-":
+		args = append(args, "fold=collapsed")
+	case "+":
+		args = append(args, "fold=expanded")
+	}
+
+	var content string
+	if hasOnlyParagraphs(n) {
+		var parts []string
+		for child := n.FirstChild; child != nil; child = child.Next {
+			parts = append(parts, r.renderInlines(child, "<br/>"))
+		}
+		content = strings.Join(parts, "<br/>")
+	} else {
+		content = "\n" + r.renderBlocks(n) + "\n"
+	}
+	args = append(args, "content="+escapeTemplateArg(content))
+	return "{{" + strings.Join(args, "|") + "}}"
+}
+
+// topLevelPipes returns the offsets of the pipes in s that would separate
+// template arguments: those outside links, templates and extension tags
+func topLevelPipes(s string) []int {
+	var pipes []int
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '<':
+			m := wikiExtensionTagRegex.FindStringSubmatchIndex(s[i:])
+			if m == nil || m[5] > m[4] {
+				continue
+			}
+			closing := "</" + s[i+m[2]:i+m[3]] + ">"
+			end := strings.Index(s[i+m[1]:], closing)
+			if end < 0 {
+				return pipes
+			}
+			i += m[1] + end + len(closing) - 1
+		case strings.HasPrefix(s[i:], "{{"), strings.HasPrefix(s[i:], "[["):
+			depth++
+			i++
+		case strings.HasPrefix(s[i:], "}}"), strings.HasPrefix(s[i:], "]]"):
+			if depth > 0 {
+				depth--
+			}
+			i++
+		case s[i] == '|' && depth == 0:
+			pipes = append(pipes, i)
+		}
+	}
+	return pipes
+}
+
+// escapeTemplateArg writes the pipes of a template argument that would end
+// it as {{!}}
+func escapeTemplateArg(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, i := range topLevelPipes(s) {
+		b.WriteString(s[last:i])
+		b.WriteString("{{!}}")
+		last = i + 1
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// templateArgs splits the inside of a template call into its name and named
+// arguments
+func templateArgs(call string) (string, map[string]string) {
+	args := make(map[string]string)
+	var parts []string
+	last := 0
+	for _, i := range topLevelPipes(call) {
+		parts = append(parts, call[last:i])
+		last = i + 1
+	}
+	parts = append(parts, call[last:])
+	for _, part := range parts[1:] {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			args[strings.TrimSpace(key)] = strings.ReplaceAll(value, "{{!}}", "|")
+		}
+	}
+	return strings.TrimSpace(parts[0]), args
+}
+
+// templateEnd returns the index of the line closing the template call that
+// starts at lines[start]
+func templateEnd(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		depth += strings.Count(lines[i], "{{") - strings.Count(lines[i], "}}")
+		if depth <= 0 {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// isCalloutTemplate reports whether a line starts a call to the callout
+// template
+func (r *reverser) isCalloutTemplate(line string) bool {
+	name := r.config.Callouts.Template
+	if name == "" || !strings.HasPrefix(line, "{{"+name) {
+		return false
+	}
+	rest := strings.TrimLeft(line[len(name)+2:], " ")
+	return strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, "}}") || rest == ""
+}
+
+// calloutTemplate converts a call to the callout template back to an
+// Obsidian callout
+func (r *reverser) calloutTemplate(lines []string) string {
+	call := strings.Join(lines, "\n")
+	call = strings.TrimSuffix(strings.TrimPrefix(call, "{{"), "}}")
+	_, args := templateArgs(call)
+
+	marker := "> [!" + strings.TrimSpace(args["type"]) + "]"
+	switch strings.TrimSpace(args["fold"]) {
+	case "collapsed":
+		marker += "-"
+	case "expanded":
+		marker += "+"
+	}
+	if title := strings.TrimSpace(args["title"]); title != "" {
+		marker += " " + r.inline(title)
+	}
+	return strings.Join(append([]string{marker}, r.calloutContent(args["content"])...), "\n")
+}
+
+// calloutContent converts the content of a callout box to quoted Markdown
+// lines. Content starting on a line of its own or spanning several holds
+// blocks rendered in full; other content is paragraphs joined with <br/>.
+func (r *reverser) calloutContent(content string) []string {
+	var out []string
+	blocks := strings.HasPrefix(content, "\n")
+	content = strings.TrimSpace(content)
+	switch {
+	case content == "":
+	case blocks || strings.Contains(content, "\n"):
+		for _, line := range strings.Split(r.blocks(content), "\n") {
+			out = append(out, strings.TrimRight("> "+line, " "))
+		}
+	default:
+		for _, part := range strings.Split(content, "<br/>") {
+			out = append(out, strings.TrimRight("> "+r.inline(part), " "))
+		}
+	}
+	return out
+}
+
+// CalloutTemplate returns the wikitext of a template named name that renders
+// the calls written with CalloutConfig.Template. It shows the theme's emoji
+// and labels and loads its styles from the Template:name/styles.css page
+// written by CalloutTemplateStyles. The #switch needs the ParserFunctions
+// extension and <templatestyles> the TemplateStyles extension.
+func (t *Theme) CalloutTemplate(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<includeonly><templatestyles src="%s/styles.css" /><div class="callout callout-{{{type|note}}}{{#switch:{{{fold|}}}|collapsed= mw-collapsible mw-collapsed|expanded= mw-collapsible}}">`+"\n", name)
+	b.WriteString(`<div class="callout-title">{{#switch:{{{type|note}}}` + "\n")
+	for _, kind := range t.calloutKinds() {
+		style := t.callout(kind)
+		title := "{{{title|" + style.Label + "}}}"
+		if style.Emoji != "" {
+			title = style.Emoji + " " + title
+		}
+		fmt.Fprintf(&b, "|%s=%s\n", kind, title)
+	}
+	b.WriteString("|#default={{{title|}}}\n}}</div>\n")
+	b.WriteString(`<div class="callout-content mw-collapsible-content">` + "\n{{{content|}}}\n</div></div></includeonly><noinclude>\n")
+	fmt.Fprintf(&b, "Renders an Obsidian callout box converted by md-to-mediawiki-plus. Styles: [[Template:%s/styles.css]].\n\n", name)
+	fmt.Fprintf(&b, "<code><nowiki>{{%s|type=warning|title=Breaking change|fold=collapsed|content=Text}}</nowiki></code>\n\n", name)
+	fmt.Fprintf(&b, "; type: %s\n", strings.Join(t.calloutKinds(), ", "))
+	b.WriteString("; title: replaces the type's label\n")
+	b.WriteString("; fold: <code>collapsed</code> or <code>expanded</code> to add a show/hide toggle\n")
+	b.WriteString("; content: the text of the box\n")
+	b.WriteString("</noinclude>\n")
+	return b.String()
+}
+
+// CalloutTemplateStyles returns the TemplateStyles CSS for the template
+// written by CalloutTemplate, with the theme's callout colors
+func (t *Theme) CalloutTemplateStyles() string {
+	var b strings.Builder
+	b.WriteString("/* Callout box styles generated by md-to-mediawiki-plus */\n")
+	b.WriteString(".callout {\n\tmargin: 1em 0;\n\tpadding: 0.5em;\n\tborder: 1px solid #a2a9b1;\n}\n")
+	b.WriteString(".callout-title {\n\tfont-weight: bold;\n}\n")
+	for _, kind := range t.calloutKinds() {
+		style := t.callout(kind)
+		var box []string
+		if style.Border != "" {
+			box = append(box, "\tborder-left: 4px solid "+style.Border+";")
+		}
+		if style.Background != "" {
+			box = append(box, "\tbackground-color: "+style.Background+";")
+		}
+		if len(box) > 0 {
+			fmt.Fprintf(&b, ".callout-%s {\n%s\n}\n", kind, strings.Join(box, "\n"))
+		}
+		if style.Text != "" {
+			fmt.Fprintf(&b, ".callout-%s .callout-title {\n\tcolor: %s;\n}\n", kind, style.Text)
+		}
+	}
+	return b.String()
+}
+
+// calloutKinds returns the callout types in alphabetical order
+func (t *Theme) calloutKinds() []string {
+	kinds := make([]string, 0, len(calloutStyles))
+	for kind := range calloutStyles {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
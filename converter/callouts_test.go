@@ -0,0 +1,82 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertCalloutTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Text",
+			input:    "> [!note]\n> A plain note\n> on two lines",
+			expected: "{{Callout|type=note|content=A plain note<br/>on two lines}}",
+		},
+		{
+			name:     "Title And Fold",
+			input:    "> [!warning]- Breaking change\n> Back up first",
+			expected: "{{Callout|type=warning|title=Breaking change|fold=collapsed|content=Back up first}}",
+		},
+		{
+			name:     "Pipes",
+			input:    "> [!tip]+ a | b\n> Use [[Page|alias]] or `x|y`",
+			expected: "{{Callout|type=tip|title=a {{!}} b|fold=expanded|content=Use [[Page|alias]] or <code style=\"" + inlineCodeStyle + "\">x{{!}}y</code>}}",
+		},
+		{
+			name:     "Blocks",
+			input:    "> [!info]\n> - one\n>\n> | a |\n> |---|\n> | b |\n>\n> ```\n> x | y\n> ```",
+			expected: "{{Callout|type=info|content=\n* one\n\n{{{!}} class=\"wikitable\"\n{{!}}-\n! a\n{{!}}-\n{{!}} b\n{{!}}}\n\n<syntaxhighlight lang=\"text\" line>\nx | y\n</syntaxhighlight>\n}}",
+		},
+		{
+			name:     "Nested",
+			input:    "> [!note]\n> > [!tip]\n> > Inner",
+			expected: "{{Callout|type=note|content=\n{{Callout|type=tip|content=Inner}}\n}}",
+		},
+	}
+
+	config := Config{Callouts: CalloutConfig{Template: "Callout"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wikitext := Convert(tt.input, config).Text
+			if wikitext != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", wikitext, tt.expected)
+			}
+			markdown := ToMarkdown(wikitext, config)
+			if again := Convert(markdown, config).Text; again != wikitext {
+				t.Errorf("round trip through %q:\ngot  %q\nwant %q", markdown, again, wikitext)
+			}
+		})
+	}
+}
+
+func TestCalloutTemplate(t *testing.T) {
+	theme := DefaultTheme()
+	template := theme.CalloutTemplate("Box")
+	for _, want := range []string{
+		`<templatestyles src="Box/styles.css" />`,
+		"|warning=⚠️ {{{title|Warning}}}\n",
+		"|faq=❓ {{{title|FAQ}}}\n",
+		"{{{content|}}}",
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("CalloutTemplate() is missing %q", want)
+		}
+	}
+
+	css := theme.CalloutTemplateStyles()
+	for _, want := range []string{
+		".callout-warning {\n\tborder-left: 4px solid #e6a700;\n\tbackground-color: #fff8e6;\n}",
+		".callout-warning .callout-title {\n\tcolor: #8a6500;\n}",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("CalloutTemplateStyles() is missing %q", want)
+		}
+	}
+	if css := PlainTheme().CalloutTemplateStyles(); strings.Contains(css, ".callout-warning") {
+		t.Errorf("plain theme styles = %q, want no callout colors", css)
+	}
+}
@@ -0,0 +1,288 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeInfo is what the info string of a fenced code block says about it,
+// e.g. python {2,4-6} title="app.py" linenos=false
+type codeInfo struct {
+	lang      string
+	highlight string // lines to highlight, e.g. "2,4-6"
+	start     string // number of the first line
+	lines     bool   // show line numbers
+	inline    bool   // render the code inside the paragraph flow
+	title     string // caption shown above the block
+}
+
+var (
+	lineRangesRegex    = regexp.MustCompile(`^\d+(?:-\d+)?(?:[\s,]+\d+(?:-\d+)?)*$`)
+	lineNumberRegex    = regexp.MustCompile(`^\d+$`)
+	codeLangRegex      = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+	wikiCodeTitleRegex = regexp.MustCompile(`^<div style="font-weight:bold;">(.*)</div>$`)
+	wikiCodeAttrRegex  = regexp.MustCompile(`([\w-]+)(?:="([^"]*)")?`)
+
+	// The characters a code title must not carry into HTML as they are
+	codeTitleEscaper   = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "<", "&lt;", ">", "&gt;")
+	codeTitleUnescaper = strings.NewReplacer("&amp;", "&", "&quot;", `"`, "&lt;", "<", "&gt;", ">")
+)
+
+// parseCodeInfo reads the language and attributes of an info string. The
+// first word is the language; the rest are {2,4-6} line ranges, key=value
+// attributes and flags in the spellings of common Markdown tools.
+func parseCodeInfo(info string) codeInfo {
+	code := codeInfo{lines: true}
+	for i, field := range infoFields(info) {
+		code.apply(field, i == 0)
+	}
+	return code
+}
+
+// apply reads one field of an info string
+func (c *codeInfo) apply(field string, first bool) {
+	switch {
+	case strings.HasPrefix(field, "{") && strings.HasSuffix(field, "}"):
+		inner := strings.TrimSpace(field[1 : len(field)-1])
+		if lineRangesRegex.MatchString(inner) {
+			c.highlight = lineRanges(inner)
+			return
+		}
+		// Pandoc attributes: {.python .numberLines startFrom="10"}
+		for _, f := range infoFields(inner) {
+			c.apply(f, false)
+		}
+	case strings.HasPrefix(field, "."):
+		if c.set(strings.ToLower(field[1:]), "") || c.lang != "" {
+			return
+		}
+		c.lang = field[1:]
+	case strings.Contains(field, "="):
+		key, value, _ := strings.Cut(field, "=")
+		c.set(strings.ToLower(key), unquote(value))
+	case first:
+		c.lang = field
+	default:
+		c.set(strings.ToLower(field), "")
+	}
+}
+
+// set applies an attribute, reporting whether the key is one it knows
+func (c *codeInfo) set(key, value string) bool {
+	switch key {
+	case "title", "filename", "file", "caption":
+		c.title = value
+	case "highlight", "hl_lines", "hl", "emphasize-lines", "mark":
+		if lineRangesRegex.MatchString(strings.TrimSpace(value)) {
+			c.highlight = lineRanges(value)
+		}
+	case "start", "startfrom", "start-line", "linenostart", "firstline":
+		if lineNumberRegex.MatchString(value) {
+			c.start = value
+		}
+	case "line", "lines", "linenos", "linenums", "numberlines", "showlinenumbers":
+		c.lines = isOn(value)
+	case "nolinenos", "nolinenums", "nolinenumbers":
+		c.lines = false
+	case "inline":
+		c.inline = isOn(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// infoFields splits an info string at the spaces outside quoted values and
+// braces
+func infoFields(info string) []string {
+	var fields []string
+	var b strings.Builder
+	var quote byte
+	braces := 0
+	for i := 0; i < len(info); i++ {
+		c := info[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (b.Len() == 0 || info[i-1] == '='):
+			quote = c
+		case c == '{':
+			braces++
+		case c == '}' && braces > 0:
+			braces--
+		case (c == ' ' || c == '\t') && braces == 0:
+			if b.Len() > 0 {
+				fields = append(fields, b.String())
+				b.Reset()
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if b.Len() > 0 {
+		fields = append(fields, b.String())
+	}
+	return fields
+}
+
+// unquote removes the quotes around an attribute value
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// isOn reads a flag value; a flag without one is on
+func isOn(value string) bool {
+	switch strings.ToLower(value) {
+	case "false", "no", "off", "0":
+		return false
+	}
+	return true
+}
+
+// lineRanges writes line ranges separated by spaces or commas as
+// syntaxhighlight wants them, e.g. "2,4-6"
+func lineRanges(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}), ",")
+}
+
+// tag returns the opening <syntaxhighlight> tag for the block
+func (c codeInfo) tag() string {
+	attrs := []string{`lang="` + c.lang + `"`}
+	if c.highlight != "" {
+		attrs = append(attrs, `highlight="`+c.highlight+`"`)
+	}
+	if c.start != "" {
+		attrs = append(attrs, `start="`+c.start+`"`)
+	}
+	if c.lines {
+		attrs = append(attrs, "line")
+	}
+	if c.inline {
+		attrs = append(attrs, "inline")
+	}
+	return "<syntaxhighlight " + strings.Join(attrs, " ") + ">"
+}
+
+// String writes the info string back in the form parseCodeInfo reads
+func (c codeInfo) String() string {
+	var fields []string
+	if c.highlight != "" {
+		fields = append(fields, "{"+c.highlight+"}")
+	}
+	if c.start != "" {
+		fields = append(fields, "start="+c.start)
+	}
+	if !c.lines {
+		fields = append(fields, "linenos=false")
+	}
+	if c.inline {
+		fields = append(fields, "inline")
+	}
+	if c.title != "" {
+		quote := `"`
+		if strings.Contains(c.title, quote) {
+			quote = "'"
+		}
+		fields = append(fields, "title="+quote+c.title+quote)
+	}
+
+	// Without attributes the default language is left out
+	lang := c.lang
+	if lang == "text" && len(fields) == 0 {
+		lang = ""
+	}
+	if lang == "" && len(fields) > 0 {
+		lang = "text"
+	}
+	return strings.Join(append([]string{lang}, fields...), " ")
+}
+
+// renderCodeBlock renders a code block as <syntaxhighlight>, with the title
+// of a fenced block as a caption above it
+func (r *renderer) renderCodeBlock(n *Node) string {
+	code := strings.TrimRight(strings.TrimLeft(n.Literal, "\n"), " \t\n")
+	info := parseCodeInfo(n.Info)
+	// A language that is not a plain lexer name could break out of the
+	// lang attribute, so it is detected instead
+	if !codeLangRegex.MatchString(info.lang) {
+		info.lang = detectLanguage(code)
+	}
+	block := fmt.Sprintf("%s\n%s\n</syntaxhighlight>", info.tag(), code)
+	if info.title != "" {
+		block = `<div style="font-weight:bold;">` + escapeText(codeTitleEscaper.Replace(info.title)) + "</div>\n" + block
+	}
+	return block
+}
+
+// codeTitle reads the text of a code block caption written by
+// renderCodeBlock
+func codeTitle(s string) string {
+	s = wikiNowikiRegex.ReplaceAllString(s, "$1")
+	return codeTitleUnescaper.Replace(s)
+}
+
+// codeBlockEnd returns the index of the line closing the <syntaxhighlight>
+// block that starts at lines[start]
+func codeBlockEnd(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.Contains(lines[i], "</syntaxhighlight>") {
+			return i
+		}
+	}
+	return len(lines) - 1
+}
+
+// codeBlock converts a <syntaxhighlight> block and its caption to a fenced
+// code block
+func (r *reverser) codeBlock(lines []string, title string) string {
+	m := wikiCodeBlockRegex.FindStringSubmatch(lines[0])
+	info := codeInfo{title: title}
+	var code []string
+	if m != nil {
+		for _, attr := range wikiCodeAttrRegex.FindAllStringSubmatch(m[1], -1) {
+			switch attr[1] {
+			case "lang":
+				info.lang = attr[2]
+			case "highlight":
+				info.highlight = attr[2]
+			case "start":
+				info.start = attr[2]
+			case "line":
+				info.lines = true
+			case "inline":
+				info.inline = true
+			}
+		}
+		if first := m[2]; first != "" {
+			code = append(code, first)
+		}
+	}
+	for _, line := range lines[1:] {
+		if i := strings.Index(line, "</syntaxhighlight>"); i >= 0 {
+			if before := line[:i]; before != "" {
+				code = append(code, before)
+			}
+			break
+		}
+		code = append(code, line)
+	}
+
+	body := strings.Join(code, "\n")
+	infoString := info.String()
+	fence := "```"
+	if strings.Contains(infoString, "`") {
+		fence = "~~~"
+	}
+	for strings.Contains(body, fence) {
+		fence += fence[:1]
+	}
+	return fence + infoString + "\n" + body + "\n" + fence
+}
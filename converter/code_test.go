@@ -0,0 +1,112 @@
+package converter
+
+import "testing"
+
+func TestConvertCodeBlockInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Language Only",
+			input:    "```bash\nls\n```",
+			expected: "<syntaxhighlight lang=\"bash\" line>\nls\n</syntaxhighlight>",
+		},
+		{
+			name:     "Highlight Braces",
+			input:    "```go {1, 3-4}\na\nb\nc\nd\n```",
+			expected: "<syntaxhighlight lang=\"go\" highlight=\"1,3-4\" line>\na\nb\nc\nd\n</syntaxhighlight>",
+		},
+		{
+			name:     "Key Value Attributes",
+			input:    "```python hl_lines=\"2 3\" linenostart=5 title='main.py'\na\nb\nc\n```",
+			expected: "<div style=\"font-weight:bold;\">main.py</div>\n<syntaxhighlight lang=\"python\" highlight=\"2,3\" start=\"5\" line>\na\nb\nc\n</syntaxhighlight>",
+		},
+		{
+			name:     "Pandoc Attributes",
+			input:    "``` {.haskell .numberLines startFrom=\"100\"}\nmain = pure ()\n```",
+			expected: "<syntaxhighlight lang=\"haskell\" start=\"100\" line>\nmain = pure ()\n</syntaxhighlight>",
+		},
+		{
+			name:     "Line Numbers Off",
+			input:    "~~~sql nolinenos\nSELECT 1\n~~~",
+			expected: "<syntaxhighlight lang=\"sql\">\nSELECT 1\n</syntaxhighlight>",
+		},
+		{
+			name:     "Title With Spaces And Markup",
+			input:    "```yaml title=\"CI config ''draft''\"\non: push\n```",
+			expected: "<div style=\"font-weight:bold;\">CI config <nowiki>''</nowiki>draft<nowiki>''</nowiki></div>\n<syntaxhighlight lang=\"yaml\" line>\non: push\n</syntaxhighlight>",
+		},
+		{
+			name:     "Title Is Escaped",
+			input:    "```html title='<b class=\"x\">A & B</b>'\n<br>\n```",
+			expected: "<div style=\"font-weight:bold;\">&lt;b class=&quot;x&quot;&gt;A &amp; B&lt;/b&gt;</div>\n<syntaxhighlight lang=\"html\" line>\n<br>\n</syntaxhighlight>",
+		},
+		{
+			name:     "Invalid Language Is Detected",
+			input:    "```x\"><script>\nSELECT 1\n```",
+			expected: "<syntaxhighlight lang=\"sql\" line>\nSELECT 1\n</syntaxhighlight>",
+		},
+		{
+			name:     "Detected Language Keeps Attributes",
+			input:    "``` {2}\n{\n  \"a\": 1\n}\n```",
+			expected: "<syntaxhighlight lang=\"json\" highlight=\"2\" line>\n{\n  \"a\": 1\n}\n</syntaxhighlight>",
+		},
+		{
+			name:     "Unknown Attributes Are Ignored",
+			input:    "```js copy showLineNumbers=false\nx()\n```",
+			expected: "<syntaxhighlight lang=\"js\">\nx()\n</syntaxhighlight>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCodeBlockInfoRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Attributes",
+			input: "```python {2,4-6} start=3 linenos=false title=\"app.py\"\na\nb\nc\nd\ne\nf\n```",
+		},
+		{
+			name:  "Inline",
+			input: "```go inline\nx := 1\n```",
+		},
+		{
+			name:  "Title With Quotes",
+			input: "```sh title='run \"all\"'\nmake\n```",
+		},
+		{
+			name:  "Title With Markup And Entities",
+			input: "```sh title=\"[[a]] <b> & ''c''\"\nmake\n```",
+		},
+		{
+			name:  "Fence Inside Code",
+			input: "````markdown\n```go\nx\n```\n````",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wikitext := Convert(tt.input, Config{}).Text
+			markdown := ToMarkdown(wikitext, Config{})
+			if markdown != tt.input {
+				t.Errorf("ToMarkdown() = %q, want %q", markdown, tt.input)
+			}
+			if again := Convert(markdown, Config{}).Text; again != wikitext {
+				t.Errorf("round trip through %q:\ngot  %q\nwant %q", markdown, again, wikitext)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Colors of the page around converted content, from MediaWiki's default
+// skin
+const (
+	pageBackground = "#ffffff"
+	pageText       = "#202122"
+)
+
+// Minimum WCAG AA contrast ratios
+const (
+	minContrastNormal = 4.5
+	minContrastLarge  = 3.0 // Text of at least 18pt, or 14pt bold
+)
+
+var (
+	hexColorRegex   = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	rgbColorRegex   = regexp.MustCompile(`^rgb\(\s*(\d{1,3})\s*,\s*(\d{1,3})\s*,\s*(\d{1,3})\s*\)$`)
+	cssCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/|<!--.*?-->|<[^>]*>`)
+	cssRuleRegex    = regexp.MustCompile(`([^{}]+)\{([^{}]*)\}`)
+	styleAttrRegex  = regexp.MustCompile(`<(\w+)[^>]*\sstyle="([^"]*)"`)
+
+	largeHeadingRegex = regexp.MustCompile(`\bh[12]\b`)
+)
+
+// The basic CSS color keywords
+var namedColors = map[string]string{
+	"black": "#000000", "silver": "#c0c0c0", "gray": "#808080", "grey": "#808080",
+	"white": "#ffffff", "maroon": "#800000", "red": "#ff0000", "purple": "#800080",
+	"fuchsia": "#ff00ff", "green": "#008000", "lime": "#00ff00", "olive": "#808000",
+	"yellow": "#ffff00", "navy": "#000080", "blue": "#0000ff", "teal": "#008080",
+	"aqua": "#00ffff",
+}
+
+// CSS values that take their color from elsewhere and cannot be checked
+var inheritedColors = map[string]bool{
+	"inherit": true, "initial": true, "unset": true, "currentcolor": true, "transparent": true,
+}
+
+// ContrastError reports the color pairs of a theme that fail WCAG AA
+type ContrastError struct {
+	Pairs []ContrastPair
+}
+
+func (e *ContrastError) Error() string {
+	lines := make([]string, 0, len(e.Pairs)+1)
+	lines = append(lines, fmt.Sprintf("%d color pair(s) fail WCAG AA contrast:", len(e.Pairs)))
+	for _, pair := range e.Pairs {
+		lines = append(lines, "  "+pair.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ContrastPair is a foreground/background color pair used on a page
+type ContrastPair struct {
+	Element    string // Where the pair is used, e.g. "heading 2" or ".mw-highlight .c"
+	Foreground string
+	Background string
+	Ratio      float64 // 0 when a color cannot be read
+	Minimum    float64
+}
+
+// Passes reports whether the pair meets its minimum ratio
+func (p ContrastPair) Passes() bool {
+	return p.Ratio >= p.Minimum
+}
+
+func (p ContrastPair) String() string {
+	if p.Ratio == 0 {
+		return fmt.Sprintf("%s: cannot read color %s on %s", p.Element, p.Foreground, p.Background)
+	}
+	return fmt.Sprintf("%s: %s on %s is %.2f:1, needs %.1f:1", p.Element, p.Foreground, p.Background, p.Ratio, p.Minimum)
+}
+
+// newContrastPair computes the ratio of a pair
+func newContrastPair(element, foreground, background string, minimum float64) ContrastPair {
+	ratio, err := ContrastRatio(foreground, background)
+	if err != nil {
+		ratio = 0
+	}
+	return ContrastPair{Element: element, Foreground: foreground, Background: background, Ratio: ratio, Minimum: minimum}
+}
+
+// ContrastRatio returns the WCAG 2 contrast ratio of two CSS colors written
+// as #rgb, #rrggbb, rgb(r, g, b) or a basic color keyword
+func ContrastRatio(foreground, background string) (float64, error) {
+	fg, err := relativeLuminance(foreground)
+	if err != nil {
+		return 0, err
+	}
+	bg, err := relativeLuminance(background)
+	if err != nil {
+		return 0, err
+	}
+	lighter, darker := math.Max(fg, bg), math.Min(fg, bg)
+	return (lighter + 0.05) / (darker + 0.05), nil
+}
+
+// relativeLuminance parses a color and returns its WCAG relative luminance
+func relativeLuminance(color string) (float64, error) {
+	color = strings.TrimSpace(color)
+	if hex, ok := namedColors[strings.ToLower(color)]; ok {
+		color = hex
+	}
+	var rgb [3]float64
+	if m := hexColorRegex.FindStringSubmatch(color); m != nil {
+		hex := m[1]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		for i := range rgb {
+			v, _ := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+			rgb[i] = float64(v)
+		}
+	} else if m := rgbColorRegex.FindStringSubmatch(strings.ToLower(color)); m != nil {
+		for i := range rgb {
+			v, _ := strconv.Atoi(m[i+1])
+			if v > 255 {
+				return 0, fmt.Errorf("invalid color %q", color)
+			}
+			rgb[i] = float64(v)
+		}
+	} else {
+		return 0, fmt.Errorf("invalid color %q", color)
+	}
+
+	for i, v := range rgb {
+		v /= 255
+		if v <= 0.03928 {
+			rgb[i] = v / 12.92
+		} else {
+			rgb[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*rgb[0] + 0.7152*rgb[1] + 0.0722*rgb[2], nil
+}
+
+// ContrastPairs lists the color pairs the theme puts on a page: headings,
+// inline code, highlights, callout boxes and the rules of its CSS
+func (t *Theme) ContrastPairs() []ContrastPair {
+	var pairs []ContrastPair
+
+	levels := make([]int, 0, len(t.HeadingColors))
+	for level := range t.HeadingColors {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	for _, level := range levels {
+		if color := t.HeadingColors[level]; color != "" {
+			// MediaWiki's first two heading levels are large text
+			minimum := minContrastNormal
+			if level <= 2 {
+				minimum = minContrastLarge
+			}
+			pairs = append(pairs, newContrastPair(fmt.Sprintf("heading %d", level), color, pageBackground, minimum))
+		}
+	}
+
+	if t.Code.Background != "" || t.Code.Color != "" {
+		pairs = append(pairs, newContrastPair("inline code", orDefault(t.Code.Color, pageText), orDefault(t.Code.Background, pageBackground), minContrastNormal))
+	}
+	if t.Highlight != "" {
+		pairs = append(pairs, newContrastPair("highlight", pageText, t.Highlight, minContrastNormal))
+	}
+
+	kinds := make([]string, 0, len(t.Callouts))
+	for kind := range t.Callouts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		style := t.Callouts[kind]
+		background := orDefault(style.Background, pageBackground)
+		if style.Text != "" || style.Background != "" {
+			pairs = append(pairs, newContrastPair(kind+" callout label", orDefault(style.Text, pageText), background, minContrastNormal))
+		}
+		if style.Background != "" {
+			pairs = append(pairs, newContrastPair(kind+" callout text", pageText, background, minContrastNormal))
+		}
+	}
+
+	return append(pairs, cssContrastPairs(t.StylingCSS())...)
+}
+
+// CheckContrast returns the theme's color pairs that fail WCAG AA
+func (t *Theme) CheckContrast() []ContrastPair {
+	return failingPairs(t.ContrastPairs())
+}
+
+// cssContrastPairs lists the color pairs set by CSS rules. Rules without a
+// background of their own are checked against the highlighted code box for
+// .mw-highlight selectors and the page otherwise.
+func cssContrastPairs(css string) []ContrastPair {
+	css = cssCommentRegex.ReplaceAllString(css, "")
+	codeBackground := pageBackground
+	type rule struct {
+		selector     string
+		declarations map[string]string
+	}
+	var rules []rule
+	for _, m := range cssRuleRegex.FindAllStringSubmatch(css, -1) {
+		selector := strings.Join(strings.Fields(m[1]), " ")
+		declarations := cssDeclarations(m[2])
+		if selector == ".mw-highlight" && declarations["background-color"] != "" {
+			codeBackground = declarations["background-color"]
+		}
+		rules = append(rules, rule{selector, declarations})
+	}
+
+	var pairs []ContrastPair
+	for _, r := range rules {
+		color := r.declarations["color"]
+		if color == "" || inheritedColors[strings.ToLower(color)] {
+			continue
+		}
+		background := r.declarations["background-color"]
+		if background == "" || inheritedColors[strings.ToLower(background)] {
+			background = pageBackground
+			if strings.HasPrefix(r.selector, ".mw-highlight ") {
+				background = codeBackground
+			}
+		}
+		minimum := minContrastNormal
+		if largeHeadingRegex.MatchString(r.selector) {
+			minimum = minContrastLarge
+		}
+		element, _, _ := strings.Cut(r.selector, ",")
+		pairs = append(pairs, newContrastPair(element, color, background, minimum))
+	}
+	return pairs
+}
+
+// cssDeclarations parses "name: value" declarations, dropping !important
+func cssDeclarations(block string) map[string]string {
+	declarations := make(map[string]string)
+	for _, declaration := range strings.Split(block, ";") {
+		name, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		if name == "background" && !strings.Contains(value, " ") {
+			name = "background-color"
+		}
+		declarations[name] = value
+	}
+	return declarations
+}
+
+// CheckWikitextContrast returns the inline style colors in wikitext that
+// fail WCAG AA, such as HTML written in the Markdown source. Elements
+// without a background are checked against the page.
+func CheckWikitextContrast(wikitext string) []ContrastPair {
+	var pairs []ContrastPair
+	for _, m := range styleAttrRegex.FindAllStringSubmatchIndex(wikitext, -1) {
+		tag := wikitext[m[2]:m[3]]
+		declarations := cssDeclarations(wikitext[m[4]:m[5]])
+		color, background := declarations["color"], declarations["background-color"]
+		if inheritedColors[strings.ToLower(color)] {
+			color = ""
+		}
+		if inheritedColors[strings.ToLower(background)] {
+			background = ""
+		}
+		if color == "" && background == "" {
+			continue
+		}
+		line := strings.Count(wikitext[:m[0]], "\n") + 1
+		element := fmt.Sprintf("line %d <%s>", line, tag)
+		pairs = append(pairs, newContrastPair(element, orDefault(color, pageText), orDefault(background, pageBackground), minContrastNormal))
+	}
+	return failingPairs(pairs)
+}
+
+func failingPairs(pairs []ContrastPair) []ContrastPair {
+	var failing []ContrastPair
+	for _, pair := range pairs {
+		if !pair.Passes() {
+			failing = append(failing, pair)
+		}
+	}
+	return failing
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,119 @@
+package converter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name       string
+		foreground string
+		background string
+		expected   float64
+		wantErr    bool
+	}{
+		{name: "Black On White", foreground: "#000000", background: "#ffffff", expected: 21},
+		{name: "Same Color", foreground: "#f5ff56", background: "#f5ff56", expected: 1},
+		{name: "Order Does Not Matter", foreground: "#ffffff", background: "#000", expected: 21},
+		{name: "Hero Blue On Yellow", foreground: "#021e57", background: "#f5ff56", expected: 14.57},
+		{name: "Gray Just Below AA", foreground: "#777777", background: "#ffffff", expected: 4.48},
+		{name: "RGB Function", foreground: "rgb(0, 0, 0)", background: "rgb(255,255,255)", expected: 21},
+		{name: "Named Color", foreground: "navy", background: "white", expected: 16.0},
+		{name: "Invalid Color", foreground: "#12345", background: "#ffffff", wantErr: true},
+		{name: "Out Of Range", foreground: "rgb(300, 0, 0)", background: "#ffffff", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratio, err := ContrastRatio(tt.foreground, tt.background)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ContrastRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(ratio-tt.expected) > 0.01 {
+				t.Errorf("ContrastRatio() = %.2f, want %.2f", ratio, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuiltinThemeContrast(t *testing.T) {
+	for _, name := range []string{ThemeTieto, ThemePlain} {
+		theme, _ := BuiltinTheme(name)
+		for _, pair := range theme.CheckContrast() {
+			t.Errorf("%s theme: %s", name, pair)
+		}
+	}
+}
+
+func TestThemeContrastPairs(t *testing.T) {
+	theme := PlainTheme()
+	theme.Callouts["warning"] = CalloutStyle{Label: "Warning", Background: "#fff8e6", Text: "#e6a700"}
+	theme.CSS = `<style>
+/* Code box */
+.mw-highlight { background-color: #222222; }
+.mw-highlight .c { color: #555555 !important; }
+.mw-parser-output h2 { color: #949494; }
+.note { color: inherit; }
+</style>`
+
+	expected := []string{
+		"warning callout label: #e6a700 on #fff8e6 is 2.00:1, needs 4.5:1",
+		".mw-highlight .c: #555555 on #222222 is 2.13:1, needs 4.5:1",
+	}
+	failing := theme.CheckContrast()
+	if len(failing) != len(expected) {
+		t.Fatalf("CheckContrast() = %v, want %d pairs", failing, len(expected))
+	}
+	for i, pair := range failing {
+		if pair.String() != expected[i] {
+			t.Errorf("pair %d = %q, want %q", i, pair, expected[i])
+		}
+	}
+}
+
+func TestCheckWikitextContrast(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:  "Readable Colors",
+			input: `<span style="color:#021e57;">Heading</span> <mark style="background-color:#f5ff56">key</mark>`,
+		},
+		{
+			name:     "Faint Text",
+			input:    "Fine\n<span style=\"color: #cccccc\">faint</span>",
+			expected: []string{"line 2 <span>: #cccccc on #ffffff is 1.61:1, needs 4.5:1"},
+		},
+		{
+			name:     "Dark Background",
+			input:    `<div style="background-color:#333333">text</div>`,
+			expected: []string{"line 1 <div>: #202122 on #333333 is 1.28:1, needs 4.5:1"},
+		},
+		{
+			name:     "Unreadable Color",
+			input:    `<span style="color:var(--accent)">x</span>`,
+			expected: []string{"line 1 <span>: cannot read color var(--accent) on #ffffff"},
+		},
+		{
+			name:  "Inherited Color",
+			input: `<span style="color:inherit">x</span>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failing := CheckWikitextContrast(tt.input)
+			if len(failing) != len(tt.expected) {
+				t.Fatalf("CheckWikitextContrast() = %v, want %v", failing, tt.expected)
+			}
+			for i, pair := range failing {
+				if pair.String() != tt.expected[i] {
+					t.Errorf("pair %d = %q, want %q", i, pair, tt.expected[i])
+				}
+			}
+		})
+	}
+}
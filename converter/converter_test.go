@@ -4,6 +4,9 @@ import (
 	"testing"
 )
 
+// inlineCodeStyle is the style of inline code in the default theme
+var inlineCodeStyle = DefaultTheme().codeStyle()
+
 func TestConvertHeaders(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -13,17 +16,17 @@ func TestConvertHeaders(t *testing.T) {
 		{
 			name:     "H1",
 			input:    "# Heading 1",
-			expected: `=<span style="color:#280071;">Heading 1</span>=`,
+			expected: `=<span style="color:#021e57;">Heading 1</span>=`,
 		},
 		{
 			name:     "H2",
 			input:    "## Heading 2",
-			expected: `==<span style="color:#5B2A86;">Heading 2</span>==`,
+			expected: `==<span style="color:#021e57;">Heading 2</span>==`,
 		},
 		{
 			name:     "H3",
 			input:    "### Heading 3",
-			expected: `===<span style="color:#7B4AA3;">Heading 3</span>===`,
+			expected: `===<span style="color:#021e57;">Heading 3</span>===`,
 		},
 		{
 			name:     "No Header",
@@ -71,7 +74,7 @@ func TestConvertBoldItalic(t *testing.T) {
 		{
 			name:     "Highlight ==",
 			input:    "==highlight==",
-			expected: `<mark style="background-color:#eacbbb">highlight</mark>`,
+			expected: `<mark style="background-color:#f5ff56">highlight</mark>`,
 		},
 	}
 
@@ -131,8 +134,8 @@ func TestConvertLists(t *testing.T) {
 		},
 		{
 			name:     "Nested List",
-			input:    "  - Nested",
-			expected: "** Nested",
+			input:    "- Item\n  - Nested",
+			expected: "* Item\n** Nested",
 		},
 	}
 
@@ -145,3 +148,61 @@ func TestConvertLists(t *testing.T) {
 		})
 	}
 }
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Asterisk Bullets Keep Italic",
+			input:    "* one *two*\n* three",
+			expected: "* one ''two''\n* three",
+		},
+		{
+			name:     "Pipe In Inline Code Cell",
+			input:    "| A | B |\n|---|---|\n| `x|y` | z |",
+			expected: "{| class=\"wikitable\"\n|-\n! A\n! B\n|-\n| <code style=\"" + inlineCodeStyle + "\">x&#124;y</code>\n| z\n|}",
+		},
+		{
+			name:     "Underscores In Code Span",
+			input:    "Use `KOBO_MELDINGSDIALOG_VEDLEGG` here",
+			expected: "Use <code style=\"" + inlineCodeStyle + "\">KOBO_MELDINGSDIALOG_VEDLEGG</code> here",
+		},
+		{
+			name:     "Intraword Underscores",
+			input:    "snake_case_name",
+			expected: "snake_case_name",
+		},
+		{
+			name:     "Emphasis In Heading",
+			input:    "## The *real* deal",
+			expected: `==<span style="color:#021e57;">The ''real'' deal</span>==`,
+		},
+		{
+			name:     "Code Block Protects Markup",
+			input:    "```\n**not bold** | x\n```",
+			expected: "<syntaxhighlight lang=\"text\" line>\n**not bold** | x\n</syntaxhighlight>",
+		},
+		{
+			name:     "Reference Link",
+			input:    "[Docs][d]\n\n[d]: https://example.com",
+			expected: "[https://example.com Docs]",
+		},
+		{
+			name:     "Mixed List Nesting",
+			input:    "1. Step\n   - Detail",
+			expected: "# Step\n#* Detail",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
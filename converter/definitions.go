@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	wikiDefinitionRegex = regexp.MustCompile(`^[;:]`)
+	wikiNestedListRegex = regexp.MustCompile(`^:[*#]`)
+)
+
+// renderDefinitionList renders terms as ';' lines and definitions as ':'
+// lines. Later blocks of a definition continue it on ':' lines of their own.
+func (r *renderer) renderDefinitionList(n *Node) string {
+	var lines []string
+	for child := n.FirstChild; child != nil; child = child.Next {
+		if child.Kind == DefinitionTermNode {
+			r.inTerm = true
+			lines = append(lines, strings.TrimRight("; "+r.renderInlines(child, " "), " "))
+			r.inTerm = false
+			continue
+		}
+		for block := child.FirstChild; block != nil; block = block.Next {
+			switch block.Kind {
+			case ParagraphNode:
+				lines = append(lines, ": "+r.renderInlines(block, " "))
+			case ListNode:
+				lines = append(lines, r.renderList(block, ":"))
+			default:
+				lines = append(lines, ": "+r.renderBlock(block))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// termSafe encodes colons inside a definition term, where MediaWiki reads
+// the first one as the start of the definition
+func (r *renderer) termSafe(s string) string {
+	if !r.inTerm {
+		return s
+	}
+	return strings.ReplaceAll(s, ":", "&#58;")
+}
+
+// boldTermDefinitions turns paragraphs whose lines all read
+// "**Term**: description" or "**Term:** description" into definition
+// lists, joining each with a definition list right before it
+func boldTermDefinitions(doc *Node) {
+	var paragraphs []*Node
+	Walk(doc, func(n *Node, entering bool) WalkStatus {
+		if entering && n.Kind == ParagraphNode && n.Parent.Kind != ListItemNode {
+			paragraphs = append(paragraphs, n)
+			return WalkSkipChildren
+		}
+		return WalkContinue
+	})
+
+	for _, paragraph := range paragraphs {
+		lines := inlineLines(paragraph)
+		terms := true
+		for _, line := range lines {
+			terms = terms && isBoldTerm(line)
+		}
+		if !terms {
+			continue
+		}
+
+		list := paragraph.Prev
+		if list == nil || list.Kind != DefinitionListNode {
+			list = NewNode(DefinitionListNode)
+			list.Line = paragraph.Line
+			paragraph.InsertBefore(list)
+		}
+		for i, line := range lines {
+			term, description := splitBoldTerm(line)
+			term.Line = paragraph.Line + i
+			def := NewNode(DefinitionNode)
+			def.AppendChild(description)
+			list.AppendChild(term)
+			list.AppendChild(def)
+		}
+		paragraph.Unlink()
+	}
+}
+
+// inlineLines splits the inline children of a block at its line breaks
+func inlineLines(block *Node) [][]*Node {
+	lines := [][]*Node{nil}
+	for child := block.FirstChild; child != nil; child = child.Next {
+		if child.Kind == SoftBreakNode || child.Kind == HardBreakNode {
+			lines = append(lines, nil)
+			continue
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], child)
+	}
+	return lines
+}
+
+// isBoldTerm reports whether a line of inlines is a bold term followed by a
+// colon and a description
+func isBoldTerm(line []*Node) bool {
+	if len(line) < 2 || line[0].Kind != StrongNode {
+		return false
+	}
+	strong, next := line[0], line[1]
+	var description string
+	switch {
+	case next.Kind == TextNode && strings.HasPrefix(next.Literal, ":"):
+		description = next.Literal[1:]
+	case strong.LastChild != nil && strong.LastChild.Kind == TextNode && strings.HasSuffix(strong.LastChild.Literal, ":"):
+		if next.Kind != TextNode {
+			return true
+		}
+		description = next.Literal
+	default:
+		return false
+	}
+	return strings.TrimSpace(description) != "" || len(line) > 2
+}
+
+// splitBoldTerm moves a line accepted by isBoldTerm into a term node and a
+// description paragraph
+func splitBoldTerm(line []*Node) (term, description *Node) {
+	strong := line[0]
+	term = NewNode(DefinitionTermNode)
+	for child := strong.FirstChild; child != nil; {
+		next := child.Next
+		term.AppendChild(child)
+		child = next
+	}
+	if last := term.LastChild; last.Kind == TextNode && strings.HasSuffix(last.Literal, ":") {
+		last.Literal = strings.TrimRight(strings.TrimSuffix(last.Literal, ":"), " \t")
+	}
+
+	description = NewNode(ParagraphNode)
+	for _, child := range line[1:] {
+		description.AppendChild(child)
+	}
+	if first := description.FirstChild; first.Kind == TextNode {
+		first.Literal = strings.TrimLeft(strings.TrimPrefix(first.Literal, ":"), " \t")
+		if first.Literal == "" {
+			first.Unlink()
+		}
+	}
+	return term, description
+}
+
+// definitionList converts a run of ';' and ':' lines to a Markdown Extra
+// definition list
+func (r *reverser) definitionList(lines []string) string {
+	var out []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case line[0] == ';':
+			// A term after a definition would continue its paragraph
+			if len(out) > 0 && !strings.HasPrefix(lines[i-1], ";") {
+				out = append(out, "")
+			}
+			term := r.inline(strings.TrimSpace(line[1:]))
+			out = append(out, strings.ReplaceAll(term, "&#58;", ":"))
+		case wikiNestedListRegex.MatchString(line):
+			end := i
+			for end+1 < len(lines) && wikiNestedListRegex.MatchString(lines[end+1]) {
+				end++
+			}
+			var nested []string
+			for _, l := range lines[i : end+1] {
+				nested = append(nested, l[1:])
+			}
+			for _, l := range strings.Split(r.list(nested), "\n") {
+				out = append(out, strings.TrimRight("  "+l, " "))
+			}
+			i = end
+		default:
+			out = append(out, strings.TrimRight(": "+r.inline(strings.TrimSpace(line[1:])), " "))
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,57 @@
+package converter
+
+import "testing"
+
+func TestConvertBoldTermDefinitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Colon After Term",
+			input:    "**Timeout**: how long to wait\n**Retries**: how often to try",
+			expected: "; Timeout\n: how long to wait\n; Retries\n: how often to try",
+		},
+		{
+			name:     "Colon Inside Term",
+			input:    "**Timeout:** how long to *wait*\n\n**Retries:** [docs](https://x.org)",
+			expected: "; Timeout\n: how long to ''wait''\n; Retries\n: [https://x.org docs]",
+		},
+		{
+			name:     "Joins Definition List Before",
+			input:    "Host\n: the server name\n\n**Port**: the server port",
+			expected: "; Host\n: the server name\n; Port\n: the server port",
+		},
+		{
+			name:     "Mixed Paragraph Is Left",
+			input:    "**Timeout**: how long to wait\nand more text",
+			expected: "'''Timeout''': how long to wait\nand more text",
+		},
+		{
+			name:     "Needs A Description",
+			input:    "**Timeout**:\n\n**Bold** text",
+			expected: "'''Timeout''':\n\n'''Bold''' text",
+		},
+		{
+			name:     "List Items Are Left",
+			input:    "- **Timeout**: how long to wait",
+			expected: "* '''Timeout''': how long to wait",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := DefaultPipeline()
+			_ = pipeline.Enable(PassBoldTermDefinitions)
+			got := Convert(tt.input, Config{Pipeline: pipeline}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if got := Convert("**Timeout**: how long to wait", Config{}).Text; got != "'''Timeout''': how long to wait" {
+		t.Errorf("Convert() without %s = %q", PassBoldTermDefinitions, got)
+	}
+}
@@ -0,0 +1,172 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity tells how serious a diagnostic is
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning" // Converted, but not the way it was written
+	SeverityError   Severity = "error"   // Part of the content is lost
+)
+
+// Diagnostic codes
+const (
+	CodeUnclosedFence   = "unclosed-fence"   // A fenced code block runs to the end of its container
+	CodeTableColumns    = "table-columns"    // A table row has more or fewer cells than the header
+	CodeUnknownCallout  = "unknown-callout"  // A callout type with no style, shown as a quote
+	CodeRawHTML         = "raw-html"         // An HTML tag MediaWiki does not allow
+	CodeListNesting     = "list-nesting"     // A bullet list nested in a numbered item
+	CodeFrontMatter     = "front-matter"     // A --- block opening the document that is not valid YAML
+	CodeUnknownLanguage = "unknown-language" // A code block language that is not a lexer name
+)
+
+// Diagnostic reports a construct that could not be converted cleanly
+type Diagnostic struct {
+	Line     int // 1-based line in the Markdown source
+	Column   int // 1-based column in the Markdown source
+	Severity Severity
+	Code     string
+	Message  string
+
+	Suggestion string // How to fix the source, if known
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s [%s]", d.Line, d.Column, d.Severity, d.Message, d.Code)
+}
+
+// Result is the outcome of a conversion
+type Result struct {
+	Text        string
+	Diagnostics []Diagnostic
+}
+
+// HTML tags MediaWiki accepts in wikitext: the tags its sanitizer allows
+// and the extension tags commonly installed
+var mediaWikiTags = map[string]bool{
+	"abbr": true, "b": true, "bdi": true, "bdo": true, "big": true, "blockquote": true,
+	"br": true, "caption": true, "center": true, "cite": true, "code": true, "data": true,
+	"dd": true, "del": true, "dfn": true, "div": true, "dl": true, "dt": true, "em": true,
+	"font": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "i": true, "ins": true, "kbd": true, "li": true, "mark": true, "ol": true,
+	"p": true, "pre": true, "q": true, "rb": true, "rp": true, "rt": true, "rtc": true,
+	"ruby": true, "s": true, "samp": true, "small": true, "span": true, "strike": true,
+	"strong": true, "sub": true, "sup": true, "table": true, "td": true, "th": true,
+	"time": true, "tr": true, "tt": true, "u": true, "ul": true, "var": true, "wbr": true,
+
+	"categorytree": true, "chem": true, "gallery": true, "includeonly": true, "indicator": true,
+	"inputbox": true, "math": true, "noinclude": true, "nowiki": true, "onlyinclude": true,
+	"poem": true, "ref": true, "references": true, "score": true, "section": true,
+	"source": true, "syntaxhighlight": true, "templatedata": true, "templatestyles": true,
+	"timeline": true,
+}
+
+var htmlTagNameRegex = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9-]*)`)
+
+// warn records a warning at a source position
+func (p *blockParser) warn(line, column int, code, format string, args ...interface{}) {
+	p.report(SeverityWarning, line, column, code, format, args...)
+}
+
+// fail records an error: content at a source position is lost
+func (p *blockParser) fail(line, column int, code, format string, args ...interface{}) {
+	p.report(SeverityError, line, column, code, format, args...)
+}
+
+func (p *blockParser) report(severity Severity, line, column int, code, format string, args ...interface{}) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// sourceColumn returns the 1-based column of s in a source line, or the
+// first non-blank column when s is not found
+func (p *blockParser) sourceColumn(line int, s string) int {
+	if line < 1 || line > len(p.source) {
+		return 1
+	}
+	text := p.source[line-1]
+	if i := strings.Index(text, s); s != "" && i >= 0 {
+		return i + 1
+	}
+	return len(text) - len(strings.TrimLeft(text, " \t")) + 1
+}
+
+// locate finds the first source line from line on that contains s
+func (p *blockParser) locate(line int, s string) (int, int) {
+	for l := line; l >= 1 && l <= len(p.source); l++ {
+		if strings.Contains(p.source[l-1], s) {
+			return l, p.sourceColumn(l, s)
+		}
+	}
+	return line, p.sourceColumn(line, "")
+}
+
+// checkHTMLTags warns about the tags in raw HTML that MediaWiki does not
+// allow, which the wiki shows as text. Only the first tag is checked when
+// onlyFirst is set, e.g. for blocks whose content is kept verbatim.
+func (p *blockParser) checkHTMLTags(html string, line int, onlyFirst bool) {
+	seen := make(map[string]bool)
+	for _, m := range htmlTagNameRegex.FindAllStringSubmatch(html, -1) {
+		tag := strings.ToLower(m[1])
+		if !mediaWikiTags[tag] && !seen[tag] {
+			seen[tag] = true
+			l, c := p.locate(line, m[0])
+			p.warn(l, c, CodeRawHTML, "MediaWiki does not allow the <%s> tag; it will be shown as text", tag)
+		}
+		if onlyFirst {
+			return
+		}
+	}
+}
+
+// checkInlineHTML checks the inline HTML of every block
+func (p *blockParser) checkInlineHTML() {
+	Walk(p.doc, func(n *Node, entering bool) WalkStatus {
+		if entering && n.Kind == HTMLInlineNode && !strings.HasPrefix(n.Literal, "</") {
+			block := n.Parent
+			for block != nil && block.Line == 0 {
+				block = block.Parent
+			}
+			if block != nil {
+				p.checkHTMLTags(n.Literal, block.Line, true)
+			}
+		}
+		return WalkContinue
+	})
+}
+
+// sortDiagnostics orders diagnostics by source position
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+}
+
+// containerName names a block for messages
+func containerName(block *Node) string {
+	switch block.Kind {
+	case BlockquoteNode:
+		return "quote"
+	case CalloutNode:
+		return "callout"
+	case ListItemNode:
+		return "list item"
+	case DefinitionNode:
+		return "definition"
+	}
+	return "document"
+}
@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvertDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Clean Document",
+			input:    "# Title\n\n1. One\n2. Two\n\n> [!note]\n> Text <br> and <kbd>Ctrl</kbd>\n\n```go\nx := 1\n```",
+			expected: nil,
+		},
+		{
+			name:     "Unclosed Fence",
+			input:    "Intro\n\n  ```go\n  code",
+			expected: []string{"3:3: warning: code fence is never closed; the code block runs to the end of the document [unclosed-fence]"},
+		},
+		{
+			name:     "Unclosed Fence In Quote",
+			input:    "> ```\n> code\n\nAfter",
+			expected: []string{"1:3: warning: code fence is never closed; the code block runs to the end of the quote [unclosed-fence]"},
+		},
+		{
+			name:  "Table Columns",
+			input: "| a | b |\n|---|---|\n| 1 | 2 | 3 |\n| 1 |\n| 1 | 2 |",
+			expected: []string{
+				"3:1: error: table row has 3 cell(s) but the header has 2; the extra cells are dropped [table-columns]",
+				"4:1: warning: table row has 1 cell(s) but the header has 2; the missing cells are left empty [table-columns]",
+			},
+		},
+		{
+			name:     "Unknown Callout",
+			input:    "Text\n\n> [!Hazard] Hot\n> Stay back",
+			expected: []string{`3:3: warning: unknown callout type "Hazard"; it will be shown as a block quote [unknown-callout]`},
+		},
+		{
+			name:  "Raw HTML",
+			input: "An <img src=\"a.png\"> and <font>ok</font>\n\n<iframe src=\"x\">\n<video></video>\n</iframe>\n\n<pre><img></pre>\n\n<!-- <img> -->",
+			expected: []string{
+				"1:4: warning: MediaWiki does not allow the <img> tag; it will be shown as text [raw-html]",
+				"3:1: warning: MediaWiki does not allow the <iframe> tag; it will be shown as text [raw-html]",
+				"4:1: warning: MediaWiki does not allow the <video> tag; it will be shown as text [raw-html]",
+			},
+		},
+		{
+			name:     "Bullets Under Numbered Item",
+			input:    "1. First\n   - nested\n2. Second\n\n- a\n  - b",
+			expected: []string{"2:4: warning: bullet list nested in a numbered item; MediaWiki may render it with double bullets [list-nesting]"},
+		},
+		{
+			name:     "Unknown Language",
+			input:    "```c#\nvar x = 1;\n```\n\n```{.python}\nx = 1\n```",
+			expected: []string{`1:4: warning: code language "c#" is not a lexer name; the language is detected from the code instead [unknown-language]`},
+		},
+		{
+			name:     "Lines Count Front Matter",
+			input:    "---\ntitle: x\n---\n<center><blink>x</blink></center>",
+			expected: []string{"4:9: warning: MediaWiki does not allow the <blink> tag; it will be shown as text [raw-html]"},
+		},
+		{
+			name:     "Invalid Front Matter",
+			input:    "---\ntitle: [oops\n---\nBody",
+			expected: []string{"1:1: warning: front matter is not valid YAML, read as Markdown: yaml: line 1: did not find expected ',' or ']' [front-matter]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range Convert(tt.input, Config{}).Diagnostics {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Convert() diagnostics = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Sequences in literal text that MediaWiki reads as markup anywhere in
+	// a line: links, templates, bold/italic quotes, signatures, external
+	// link brackets, behavior switches and HTML tags
+	wikiMarkupRegex = regexp.MustCompile(`\[\[|\{\{|'{2,}|~{3,}|\[(?i:https?|ftps?|mailto|irc|ircs|news|sftp|ssh):|__[A-Z]+__|<[A-Za-z/!]`)
+
+	// Line starts that MediaWiki reads as lists, definitions, rules,
+	// headings or tables
+	wikiLineStartRegex = regexp.MustCompile(`(?m)^(?:[*#:;]|-{4,}|\{\||=.*=[ \t]*$)`)
+
+	// Line starts that continue a table: cells, header cells, rows and the
+	// table end
+	tableLineStartRegex = regexp.MustCompile(`(?m)^[|!]`)
+)
+
+// escapeText protects literal text from being read as wiki markup. Tags
+// are written as entities and other sequences are wrapped in <nowiki>.
+func escapeText(s string) string {
+	return wikiMarkupRegex.ReplaceAllStringFunc(s, func(m string) string {
+		switch {
+		case m[0] == '<':
+			return "&lt;" + m[1:]
+		case m[0] == '[' && m[1] != '[':
+			return "<nowiki>[</nowiki>" + m[1:]
+		}
+		return "<nowiki>" + m + "</nowiki>"
+	})
+}
+
+// escapeLineStarts protects the lines of rendered prose that start with a
+// character MediaWiki reads as block markup
+func escapeLineStarts(s string) string {
+	return wikiLineStartRegex.ReplaceAllStringFunc(s, func(m string) string {
+		return "<nowiki>" + m[:1] + "</nowiki>" + m[1:]
+	})
+}
+
+// escapeTableLineStarts protects the lines of prose inside a table cell
+// that start with a character MediaWiki reads as table syntax
+func escapeTableLineStarts(s string) string {
+	return tableLineStartRegex.ReplaceAllString(s, "<nowiki>$0</nowiki>")
+}
+
+// escapeCell protects the start of table cell content, where -, +, } and
+// ! could be read as table syntax, and header cells containing !!, which
+// separates header cells
+func escapeCell(s string, header bool) string {
+	if s != "" && strings.ContainsRune("-+}!", rune(s[0])) {
+		s = "<nowiki>" + s[:1] + "</nowiki>" + s[1:]
+	}
+	if header {
+		s = strings.ReplaceAll(s, "!!", "<nowiki>!!</nowiki>")
+	}
+	return s
+}
+
+// escapeCode protects the content of a code span. <code> does not stop
+// MediaWiki from parsing markup, so content that would be read as markup
+// is wrapped in <nowiki>.
+func escapeCode(s string) string {
+	if !wikiMarkupRegex.MatchString(s) {
+		return s
+	}
+	return "<nowiki>" + s + "</nowiki>"
+}
+
+// escapeQuotes writes an apostrophe at either end of text as an entity
+// when it touches the quotes of emphasis, where it would change the bold
+// and italic markup
+func escapeQuotes(text string, prev, next, parent *Node) string {
+	if strings.HasPrefix(text, "'") && quotesAt(prev, parent) {
+		text = "&#39;" + text[1:]
+	}
+	if strings.HasSuffix(text, "'") && quotesAt(next, parent) {
+		text = text[:len(text)-1] + "&#39;"
+	}
+	return text
+}
+
+// quotesAt reports whether the wikitext next to a text run is emphasis
+// quotes: those of an emphasis sibling, or of the enclosing emphasis when
+// the run is at its edge
+func quotesAt(sibling, parent *Node) bool {
+	n := sibling
+	if n == nil {
+		n = parent
+	}
+	return n != nil && (n.Kind == EmphasisNode || n.Kind == StrongNode)
+}
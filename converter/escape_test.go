@@ -0,0 +1,87 @@
+package converter
+
+import "testing"
+
+func TestConvertEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Link And Template Braces",
+			input:    `Write \[\[Page]] or {{Template}}`,
+			expected: "Write <nowiki>[[</nowiki>Page]] or <nowiki>{{</nowiki>Template}}",
+		},
+		{
+			name:     "Quotes And Signatures",
+			input:    "The ''quoted'' word, signed ~~~~",
+			expected: "The <nowiki>''</nowiki>quoted<nowiki>''</nowiki> word, signed <nowiki>~~~~</nowiki>",
+		},
+		{
+			name:     "Apostrophe Next To Emphasis",
+			input:    "l'*amour* and *it's* and **'quoted'**",
+			expected: "l&#39;''amour'' and ''it's'' and '''&#39;quoted&#39;'''",
+		},
+		{
+			name:     "External Link Bracket And Behavior Switch",
+			input:    `See [https://example.com here] and \_\_NOTOC\_\_`,
+			expected: "See <nowiki>[</nowiki>https://example.com here] and <nowiki>__NOTOC__</nowiki>",
+		},
+		{
+			name:     "Escaped HTML",
+			input:    `Use \<div> or \</div>, not a < b`,
+			expected: "Use &lt;div> or &lt;/div>, not a < b",
+		},
+		{
+			name:     "Line Starts",
+			input:    "\\* not a list\n\\# not numbered\n\\: not indented\n; not a term\n\\---- not a rule\n{| not a table\n= not a heading =",
+			expected: "<nowiki>*</nowiki> not a list\n<nowiki>#</nowiki> not numbered\n<nowiki>:</nowiki> not indented\n<nowiki>;</nowiki> not a term\n<nowiki>-</nowiki>--- not a rule\n<nowiki>{</nowiki>| not a table\n<nowiki>=</nowiki> not a heading =",
+		},
+		{
+			name:     "Mid Line Is Left Alone",
+			input:    "a * b # c : d ; e ---- f",
+			expected: "a * b # c : d ; e ---- f",
+		},
+		{
+			name:     "Table Cells",
+			input:    "| a | b |\n|---|---|\n| {{x}} | \\|} |",
+			expected: "{| class=\"wikitable\"\n|-\n! a\n! b\n|-\n| <nowiki>{{</nowiki>x}}\n| &#124;}\n|}",
+		},
+		{
+			name:     "Code Span",
+			input:    "`''x''` and `<br>` and `plain`",
+			expected: "<code style=\"" + inlineCodeStyle + "\"><nowiki>''x''</nowiki></code> and <code style=\"" + inlineCodeStyle + "\"><nowiki><br></nowiki></code> and <code style=\"" + inlineCodeStyle + "\">plain</code>",
+		},
+		{
+			name:     "Generated Markup Untouched",
+			input:    "**bold** *it* [[Page]] [x](https://x.org)",
+			expected: "'''bold''' ''it'' [[Page]] [https://x.org x]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEscapingRoundTrip(t *testing.T) {
+	inputs := []string{
+		`Write \[\[Page]] or {{Template}} with ''quotes'' ~~~~`,
+		"l'*amour*",
+		"\\* not a list\n\\---- not a rule",
+		"Use `[[Page]]` and \\<div>",
+	}
+	for _, input := range inputs {
+		wikitext := Convert(input, Config{}).Text
+		markdown := ToMarkdown(wikitext, Config{})
+		if again := Convert(markdown, Config{}).Text; again != wikitext {
+			t.Errorf("round trip of %q through %q:\ngot  %q\nwant %q", input, markdown, again, wikitext)
+		}
+	}
+}
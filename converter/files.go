@@ -0,0 +1,191 @@
+package converter
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FileConfig controls how local images and embedded files map to wiki file
+// names. By default a file is named after its base name, so
+// assets/diagram.png becomes [[File:diagram.png]].
+type FileConfig struct {
+	Prefix      string // Prepended to every file name, e.g. "Handbook-"
+	KeepFolders bool   // Keep folders in the name, so assets/diagram.png becomes assets-diagram.png
+	Separator   string // Joins folders when KeepFolders is set (default "-")
+}
+
+// Asset is a local file referenced by an image or embed
+type Asset struct {
+	Source   string // Path as written in the Markdown, URL-unescaped
+	FileName string // Wiki file name the page links to
+}
+
+var (
+	embedRegex     = regexp.MustCompile(`^!\[\[([^\[\]\n|]+)(?:\|([^\[\]\n]*))?\]\]`)
+	imageSizeRegex = regexp.MustCompile(`^\d+(?:x\d+)?$`)
+	altSizeRegex   = regexp.MustCompile(`^(.*?)\s*\|\s*(\d+(?:x\d+)?)$`)
+
+	// Characters MediaWiki does not allow in page and file titles
+	fileNameReplacer = strings.NewReplacer("#", "-", "<", "-", ">", "-", "[", "-", "]", "-", "|", "-", "{", "-", "}", "-", ":", "-")
+
+	// Characters of alt text and captions that end a File: link option
+	fileLinkEscaper = strings.NewReplacer("|", "&#124;", "[", "&#91;", "]", "&#93;")
+)
+
+// imageExtensions lists the file types MediaWiki displays inline
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true,
+	".webp": true, ".bmp": true, ".tif": true, ".tiff": true,
+}
+
+// FileName maps a local file path to a wiki file name
+func (c FileConfig) FileName(source string) string {
+	name := path.Clean(strings.ReplaceAll(source, "\\", "/"))
+	if c.KeepFolders {
+		var segments []string
+		for _, segment := range strings.Split(name, "/") {
+			if segment != "" && segment != "." && segment != ".." {
+				segments = append(segments, segment)
+			}
+		}
+		separator := c.Separator
+		if separator == "" {
+			separator = "-"
+		}
+		name = strings.Join(segments, separator)
+	} else {
+		name = path.Base(name)
+	}
+	return c.Prefix + fileNameReplacer.Replace(name)
+}
+
+// isImage reports whether a file is shown inline rather than linked
+func isImage(name string) bool {
+	return imageExtensions[strings.ToLower(path.Ext(name))]
+}
+
+// isNote reports whether an embed target is another note rather than a file
+func isNote(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == "" || ext == ".md"
+}
+
+// parseEmbed recognises an Obsidian ![[file|options]] embed. Options are
+// a size such as 300 or 300x200, alt text, or alt text followed by a size.
+func (p *inlineParser) parseEmbed(block *Node) bool {
+	m := embedRegex.FindStringSubmatch(p.subject[p.pos:])
+	if m == nil || strings.TrimSpace(m[1]) == "" {
+		return false
+	}
+	p.pos += len(m[0])
+	embed := &Node{Kind: EmbedNode, Destination: strings.TrimSpace(m[1])}
+	for _, option := range strings.Split(m[2], "|") {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == "":
+		case imageSizeRegex.MatchString(option):
+			embed.Size = option
+		case embed.FirstChild == nil:
+			embed.AppendChild(newText(option))
+		}
+	}
+	block.AppendChild(embed)
+	return true
+}
+
+// renderEmbed renders embedded images as File: links, embedded notes as
+// transclusions and other files as Media: links
+func (r *renderer) renderEmbed(n *Node) string {
+	page, heading := splitTarget(n.Destination)
+	alt := n.TextContent()
+	switch {
+	case isNote(page):
+		if page == "" {
+			// Embedding a section of the same page would repeat it
+			return r.internalLink("", heading, heading)
+		}
+		return "{{:" + r.config.WikiLinks.PageTitle(page) + "}}"
+	case isImage(page):
+		return r.fileLink(page, n.Size, alt)
+	}
+	if alt == "" {
+		alt = path.Base(page)
+	}
+	return "[[Media:" + r.config.Files.FileName(page) + "|" + fileLinkSafe(alt) + "]]"
+}
+
+// renderLocalImage renders a Markdown image of a local file. Obsidian's
+// ![alt|300](image.png) size syntax is honoured.
+func (r *renderer) renderLocalImage(n *Node, alt string) string {
+	source := localPath(n.Destination)
+	size := n.Size
+	if m := altSizeRegex.FindStringSubmatch(alt); m != nil {
+		alt, size = m[1], m[2]
+	}
+	if source == "" {
+		return alt
+	}
+	return r.fileLink(source, size, alt)
+}
+
+// fileLink builds a [[File:name|300px|alt]] image link
+func (r *renderer) fileLink(source, size, alt string) string {
+	parts := []string{"File:" + r.config.Files.FileName(source)}
+	if size != "" {
+		parts = append(parts, size+"px")
+	}
+	if alt != "" {
+		parts = append(parts, fileLinkSafe(alt))
+	}
+	return "[[" + strings.Join(parts, "|") + "]]"
+}
+
+// fileLinkSafe encodes pipes that would start a new File: link option and
+// brackets that would close the link or open another one
+func fileLinkSafe(s string) string {
+	return fileLinkEscaper.Replace(s)
+}
+
+// localPath returns the unescaped path of a local link target without any
+// fragment or query, or "" for remote targets
+func localPath(dest string) string {
+	if externalURLRegex.MatchString(dest) {
+		return ""
+	}
+	if unescaped, err := url.PathUnescape(dest); err == nil {
+		dest = unescaped
+	}
+	if i := strings.IndexAny(dest, "#?"); i >= 0 {
+		dest = dest[:i]
+	}
+	return strings.TrimSpace(dest)
+}
+
+// CollectAssets lists the local files a document shows or links through
+// images and embeds, in order of first use. Embedded notes are not assets.
+func CollectAssets(doc *Node, config Config) []Asset {
+	var assets []Asset
+	seen := make(map[string]bool)
+	Walk(doc, func(n *Node, entering bool) WalkStatus {
+		if !entering {
+			return WalkContinue
+		}
+		source := ""
+		switch n.Kind {
+		case ImageNode:
+			source = localPath(n.Destination)
+		case EmbedNode:
+			if page, _ := splitTarget(n.Destination); !isNote(page) {
+				source = page
+			}
+		}
+		if source != "" && !seen[source] {
+			seen[source] = true
+			assets = append(assets, Asset{Source: source, FileName: config.Files.FileName(source)})
+		}
+		return WalkContinue
+	})
+	return assets
+}
@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   FileConfig
+		input    string
+		expected string
+	}{
+		{
+			name:     "Base Name",
+			input:    "assets/diagrams/flow.png",
+			expected: "flow.png",
+		},
+		{
+			name:     "Prefix",
+			config:   FileConfig{Prefix: "Handbook-"},
+			input:    "flow.png",
+			expected: "Handbook-flow.png",
+		},
+		{
+			name:     "Keep Folders",
+			config:   FileConfig{KeepFolders: true},
+			input:    "./assets/diagrams/flow.png",
+			expected: "assets-diagrams-flow.png",
+		},
+		{
+			name:     "Keep Folders With Separator",
+			config:   FileConfig{KeepFolders: true, Separator: "_"},
+			input:    "../assets/flow.png",
+			expected: "assets_flow.png",
+		},
+		{
+			name:     "Invalid Characters",
+			input:    "flow[v2]#1.png",
+			expected: "flow-v2--1.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.FileName(tt.input)
+			if got != tt.expected {
+				t.Errorf("FileName() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertImages(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		input    string
+		expected string
+	}{
+		{
+			name:     "Markdown Image",
+			input:    "![Alt text](path/to/img.png)",
+			expected: "[[File:img.png|Alt text]]",
+		},
+		{
+			name:     "Escaped Path",
+			input:    "![](my%20diagram.png)",
+			expected: "[[File:my diagram.png]]",
+		},
+		{
+			name:     "Markdown Image With Size",
+			input:    "![Logo|120x40](logo.svg)",
+			expected: "[[File:logo.svg|120x40px|Logo]]",
+		},
+		{
+			name:     "Alt Text With Brackets And Pipes",
+			input:    "![a \\]\\] b \\[\\[c | d](pic.png)",
+			expected: "[[File:pic.png|a &#93;&#93; b &#91;&#91;c &#124; d]]",
+		},
+		{
+			name:     "Remote Image",
+			input:    "![Logo](https://example.com/logo.png)",
+			expected: "[https://example.com/logo.png Logo]",
+		},
+		{
+			name:     "Embed With Size",
+			input:    "![[diagram.png|300]]",
+			expected: "[[File:diagram.png|300px]]",
+		},
+		{
+			name:     "Embed With Alt And Size",
+			input:    "![[diagram.png|Data flow|300]]",
+			expected: "[[File:diagram.png|300px|Data flow]]",
+		},
+		{
+			name:     "Embed Prefixed",
+			config:   Config{Files: FileConfig{Prefix: "Docs-"}},
+			input:    "![[attachments/diagram.png]]",
+			expected: "[[File:Docs-diagram.png]]",
+		},
+		{
+			name:     "Embedded Note",
+			config:   Config{WikiLinks: WikiLinkConfig{Namespace: "Docs"}},
+			input:    "![[Release Notes]]",
+			expected: "{{:Docs:Release Notes}}",
+		},
+		{
+			name:     "Embedded File",
+			input:    "![[specs/api.pdf]]",
+			expected: "[[Media:api.pdf|api.pdf]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, tt.config).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCollectAssets(t *testing.T) {
+	input := "![a](img/a.png) ![[b.png|200]] ![[Note]]\n\n![again](img/a.png) ![r](https://example.com/r.png) ![[c.pdf]]"
+	config := Config{Files: FileConfig{KeepFolders: true}}
+	expected := []Asset{
+		{Source: "img/a.png", FileName: "img-a.png"},
+		{Source: "b.png", FileName: "b.png"},
+		{Source: "c.pdf", FileName: "c.pdf"},
+	}
+
+	got := CollectAssets(Parse(input), config)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("CollectAssets() = %v, want %v", got, expected)
+	}
+}
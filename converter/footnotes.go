@@ -0,0 +1,135 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	footnoteDefinitionRegex = regexp.MustCompile(`^\[\^([^\]\s]+)\]:[ \t]*`)
+	footnoteRefRegex        = regexp.MustCompile(`^\[\^([^\]\s]+)\]`)
+	referencesTagRegex      = regexp.MustCompile(`(?i)<references\s*/?>`)
+)
+
+// parseFootnoteRef recognises a [^label] reference to a defined footnote.
+// References to undefined footnotes stay literal text.
+func (p *inlineParser) parseFootnoteRef(block *Node) bool {
+	m := footnoteRefRegex.FindStringSubmatch(p.subject[p.pos:])
+	if m == nil {
+		return false
+	}
+	if !p.footnotes[footnoteKey(m[1])] {
+		return false
+	}
+	p.pos += len(m[0])
+	block.AppendChild(&Node{Kind: FootnoteRefNode, Label: m[1]})
+	return true
+}
+
+// parseCaret opens an Obsidian ^[inline footnote] or keeps a literal '^'
+func (p *inlineParser) parseCaret(block *Node) bool {
+	start := p.pos
+	p.pos++
+	if p.peek() != '[' {
+		block.AppendChild(newText("^"))
+		return true
+	}
+	p.pos++
+	node := newText("^[")
+	block.AppendChild(node)
+	p.addBracket(node, start+1, false)
+	p.brackets.footnote = true
+	return true
+}
+
+// closeInlineFootnote turns the content after a ^[ opener into an inline
+// footnote
+func (p *inlineParser) closeInlineFootnote(block *Node, opener *bracket) {
+	footnote := &Node{Kind: InlineFootnoteNode}
+	for n := opener.node.Next; n != nil; {
+		next := n.Next
+		footnote.AppendChild(n)
+		n = next
+	}
+	block.AppendChild(footnote)
+	p.processEmphasis(opener.prevDelimiter)
+	p.brackets = opener.prev
+	opener.node.Unlink()
+}
+
+// footnoteKey matches footnote labels case-insensitively
+func footnoteKey(label string) string {
+	return strings.ToLower(label)
+}
+
+// collectFootnotes maps footnote label keys to their first definition
+func collectFootnotes(doc *Node) map[string]*Node {
+	defs := make(map[string]*Node)
+	Walk(doc, func(n *Node, entering bool) WalkStatus {
+		if entering && n.Kind == FootnoteDefinitionNode {
+			key := footnoteKey(n.Label)
+			if _, exists := defs[key]; !exists {
+				defs[key] = n
+			}
+			return WalkSkipChildren
+		}
+		return WalkContinue
+	})
+	return defs
+}
+
+// refName turns a footnote label into a <ref> name. MediaWiki rejects
+// names that are plain integers, so numeric labels get a prefix.
+func refName(label string) string {
+	name := strings.NewReplacer(`"`, "", "<", "", ">", "").Replace(label)
+	if _, err := strconv.Atoi(name); err == nil {
+		name = "fn" + name
+	}
+	return name
+}
+
+// renderFootnoteRef renders the first reference to a footnote with its
+// content and later ones as a reuse of the named ref
+func (r *renderer) renderFootnoteRef(n *Node) string {
+	key := footnoteKey(n.Label)
+	def, ok := r.footnotes[key]
+	if !ok {
+		// A tree pass removed the definition
+		return r.cellSafe("[^" + n.Label + "]")
+	}
+	r.hasRefs = true
+	name := refName(def.Label)
+	if r.usedFootnotes[key] {
+		return fmt.Sprintf(`<ref name="%s" />`, name)
+	}
+	r.usedFootnotes[key] = true
+
+	// Paragraphs stay on one line so a ref inside a list item or table
+	// cell doesn't end it
+	var parts []string
+	for child := def.FirstChild; child != nil; child = child.Next {
+		if child.Kind == ParagraphNode {
+			parts = append(parts, r.renderInlines(child, " "))
+		} else {
+			parts = append(parts, r.renderBlock(child))
+		}
+	}
+	return fmt.Sprintf(`<ref name="%s">%s</ref>`, name, strings.Join(parts, "<br/>"))
+}
+
+// renderInlineFootnote renders an Obsidian inline footnote as an unnamed ref
+func (r *renderer) renderInlineFootnote(n *Node) string {
+	r.hasRefs = true
+	return "<ref>" + r.renderInlines(n, " ") + "</ref>"
+}
+
+// renderReferences returns the section listing the page's footnotes, or ""
+// when there are none or the page already places <references/> itself
+func (r *renderer) renderReferences(body string) string {
+	if !r.hasRefs || referencesTagRegex.MatchString(body) {
+		return ""
+	}
+	return r.theme.heading(2, "References") + "\n\n<references/>"
+}
@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertFootnotes(t *testing.T) {
+	references := "==<span style=\"color:#021e57;\">References</span>==\n\n<references/>"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Named Reference",
+			input:    "Claim[^src].\n\n[^src]: The source.",
+			expected: "Claim<ref name=\"src\">The source.</ref>.\n\n" + references,
+		},
+		{
+			name:     "Numeric Label",
+			input:    "Claim[^1].\n\n[^1]: The source.",
+			expected: "Claim<ref name=\"fn1\">The source.</ref>.\n\n" + references,
+		},
+		{
+			name:     "Repeated Reference",
+			input:    "One[^a], two[^A].\n\n[^a]: Shared.",
+			expected: "One<ref name=\"a\">Shared.</ref>, two<ref name=\"a\" />.\n\n" + references,
+		},
+		{
+			name:     "Definition Before Use",
+			input:    "[^a]: Early.\n\nText[^a]",
+			expected: "Text<ref name=\"a\">Early.</ref>\n\n" + references,
+		},
+		{
+			name:     "Consecutive Definitions",
+			input:    "A[^a] B[^b]\n\n[^a]: First.\n[^b]: Second.",
+			expected: "A<ref name=\"a\">First.</ref> B<ref name=\"b\">Second.</ref>\n\n" + references,
+		},
+		{
+			name:     "Multi Paragraph Definition",
+			input:    "A[^a]\n\n[^a]: First line\n    continued.\n\n    Second paragraph.\n\nAfter",
+			expected: "A<ref name=\"a\">First line continued.<br/>Second paragraph.</ref>\n\nAfter\n\n" + references,
+		},
+		{
+			name:     "Inline Footnote",
+			input:    "Text^[With *emphasis* and [a link](https://example.com)].",
+			expected: "Text<ref>With ''emphasis'' and [https://example.com a link]</ref>.\n\n" + references,
+		},
+		{
+			name:     "Undefined Reference",
+			input:    "Text[^missing] and 2^3",
+			expected: "Text[^missing] and 2^3",
+		},
+		{
+			name:     "Existing References Tag",
+			input:    "Text[^a]\n\n<references />\n\n[^a]: Note.",
+			expected: "Text<ref name=\"a\">Note.</ref>\n\n<references />",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package converter
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterConfig maps YAML front matter keys to MediaWiki page metadata
+type FrontMatterConfig struct {
+	CategoryKeys []string // Keys listing categories, e.g. tags
+	TitleKey     string   // Key holding the {{DISPLAYTITLE:}}; "" skips it
+	Template     string   // Infobox template name; "" skips the infobox
+	TemplateKeys []string // Keys passed to the template in order; nil passes every other key
+	IgnoreKeys   []string // Keys never passed to the template
+}
+
+// DefaultFrontMatterConfig returns the mapping Convert uses when
+// Config.FrontMatter is nil: tags become categories, title becomes the
+// display title and the remaining keys except aliases fill {{DocInfo}}
+func DefaultFrontMatterConfig() *FrontMatterConfig {
+	return &FrontMatterConfig{
+		CategoryKeys: []string{"tags"},
+		TitleKey:     "title",
+		Template:     "DocInfo",
+		IgnoreKeys:   []string{"aliases"},
+	}
+}
+
+// frontMatterField is one top-level front matter key with its value
+type frontMatterField struct {
+	key   string
+	value *yaml.Node
+}
+
+// categoryNameReplacer replaces the characters MediaWiki does not allow in
+// titles, which would turn a category link into a sort key, a section link
+// or broken markup
+var categoryNameReplacer = strings.NewReplacer("#", "-", "<", "-", ">", "-", "[", "-", "]", "-", "|", "-", "{", "-", "}", "-")
+
+// errNotMapping is returned for front matter that is valid YAML but not a
+// set of keys
+var errNotMapping = errors.New("front matter is not a mapping")
+
+// parseFrontMatter decodes front matter into its top-level fields in
+// source order
+func parseFrontMatter(literal string) ([]frontMatterField, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(literal), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errNotMapping
+	}
+	var fields []frontMatterField
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		fields = append(fields, frontMatterField{key: root.Content[i].Value, value: root.Content[i+1]})
+	}
+	return fields, nil
+}
+
+// yamlValues flattens a scalar or sequence into strings
+func yamlValues(n *yaml.Node) []string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Tag == "!!null" || n.Value == "" {
+			return nil
+		}
+		return []string{n.Value}
+	case yaml.SequenceNode:
+		var values []string
+		for _, item := range n.Content {
+			values = append(values, yamlValues(item)...)
+		}
+		return values
+	case yaml.AliasNode:
+		return yamlValues(n.Alias)
+	}
+	return nil
+}
+
+// categoryNames splits tags written as a list or as a space or comma
+// separated string, dropping Obsidian's leading '#'
+func categoryNames(n *yaml.Node) []string {
+	var names []string
+	for _, value := range yamlValues(n) {
+		for _, name := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if name = strings.TrimPrefix(name, "#"); name != "" {
+				names = append(names, categoryNameReplacer.Replace(name))
+			}
+		}
+	}
+	return names
+}
+
+// templateSafe escapes characters that would end a template parameter
+func templateSafe(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", "{{!}}")
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFrontMatter returns the wikitext placed before the page body
+// (display title and infobox) and after it (categories). Front matter that
+// is not valid YAML is kept as a code block so nothing is lost.
+func (r *renderer) renderFrontMatter(n *Node) (header, footer string) {
+	fields, err := parseFrontMatter(n.Literal)
+	if err != nil {
+		return "<syntaxhighlight lang=\"yaml\">\n" + n.Literal + "\n</syntaxhighlight>", ""
+	}
+	config := r.config.FrontMatter
+	if config == nil {
+		config = DefaultFrontMatterConfig()
+	}
+
+	var headerLines, categories, params []string
+	byKey := make(map[string]*yaml.Node)
+	for _, field := range fields {
+		byKey[field.key] = field.value
+	}
+
+	if config.TitleKey != "" {
+		if value, ok := byKey[config.TitleKey]; ok {
+			if title := strings.Join(yamlValues(value), " "); title != "" {
+				headerLines = append(headerLines, "{{DISPLAYTITLE:"+templateSafe(title)+"}}")
+			}
+		}
+	}
+
+	for _, key := range config.CategoryKeys {
+		if value, ok := byKey[key]; ok {
+			for _, name := range categoryNames(value) {
+				categories = append(categories, "[[Category:"+name+"]]")
+			}
+		}
+	}
+
+	if config.Template != "" {
+		keys := config.TemplateKeys
+		if keys == nil {
+			for _, field := range fields {
+				if field.key != config.TitleKey && !containsKey(config.CategoryKeys, field.key) {
+					keys = append(keys, field.key)
+				}
+			}
+		}
+		for _, key := range keys {
+			value, ok := byKey[key]
+			if !ok || containsKey(config.IgnoreKeys, key) {
+				continue
+			}
+			if values := yamlValues(value); len(values) > 0 {
+				params = append(params, templateSafe(key)+"="+templateSafe(strings.Join(values, ", ")))
+			}
+		}
+		if len(params) > 0 {
+			headerLines = append(headerLines, "{{"+config.Template+"|"+strings.Join(params, "|")+"}}")
+		}
+	}
+
+	return strings.Join(headerLines, "\n"), strings.Join(categories, "\n")
+}
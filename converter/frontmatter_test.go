@@ -0,0 +1,74 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *FrontMatterConfig
+		input    string
+		expected string
+	}{
+		{
+			name:     "Default Mapping",
+			input:    "---\ntitle: Runbook\ntags: [ops, \"#oncall\"]\naliases: [rb]\nowner: Olga\nstatus: draft\n---\nBody",
+			expected: "{{DISPLAYTITLE:Runbook}}\n{{DocInfo|owner=Olga|status=draft}}\n\nBody\n\n[[Category:ops]]\n[[Category:oncall]]",
+		},
+		{
+			name:     "Tags As String",
+			input:    "---\ntags: ops, oncall guide\n---\nBody",
+			expected: "Body\n\n[[Category:ops]]\n[[Category:oncall]]\n[[Category:guide]]",
+		},
+		{
+			name:     "Template Values Escaped",
+			input:    "---\nowner: [Ann, Bob]\nnote: a | b\n---\nBody",
+			expected: "{{DocInfo|owner=Ann, Bob|note=a {{!}} b}}\n\nBody",
+		},
+		{
+			name: "Custom Mapping",
+			config: &FrontMatterConfig{
+				CategoryKeys: []string{"topics"},
+				Template:     "Infobox",
+				TemplateKeys: []string{"status", "owner"},
+			},
+			input:    "---\ntitle: Runbook\ntopics: [a]\nowner: Olga\nstatus: draft\n---\nBody",
+			expected: "{{Infobox|status=draft|owner=Olga}}\n\nBody\n\n[[Category:a]]",
+		},
+		{
+			name:     "Empty Front Matter",
+			input:    "---\n---\nBody",
+			expected: "Body",
+		},
+		{
+			name:     "Category Names Are Titles",
+			input:    "---\ntags: [a|b, \"x]]y\", \"{{t}}\"]\n---\nBody",
+			expected: "Body\n\n[[Category:a-b]]\n[[Category:x--y]]\n[[Category:--t--]]",
+		},
+		{
+			name:     "Invalid YAML Is Markdown",
+			input:    "---\ntitle: [oops\n---\nBody",
+			expected: "----\n\n==<span style=\"color:#021e57;\">title: [oops</span>==\n\nBody",
+		},
+		{
+			name:     "Rules Around Prose",
+			input:    "---\n\nIntro text\n\n---\n## Next",
+			expected: "----\n\nIntro text\n\n----\n\n==<span style=\"color:#021e57;\">Next</span>==",
+		},
+		{
+			name:     "Unclosed Is A Rule",
+			input:    "---\nBody",
+			expected: "----\n\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{FrontMatter: tt.config}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Regenerate the expectations with: go test ./converter -run TestGolden -update
+var update = flag.Bool("update", false, "rewrite the golden .txt files in testdata")
+
+// Fixtures whose wikitext cannot survive a trip back to Markdown, with the
+// reason
+var noRoundTrip = map[string]string{
+	"nested-blocks": "ToMarkdown does not rebuild code blocks and quotes inside list items",
+}
+
+// TestGolden converts every testdata/*.md fixture and compares the result
+// with the .txt file next to it
+func TestGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures in testdata")
+	}
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".md")
+		t.Run(name, func(t *testing.T) {
+			input, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Convert(string(input), Config{}).Text
+
+			golden := strings.TrimSuffix(fixture, ".md") + ".txt"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from %s (run with -update to accept):\n%s", fixture, golden, lineDiff(string(want), got))
+			}
+		})
+	}
+}
+
+// TestGoldenRoundTrip converts each fixture to wikitext, back to Markdown
+// and to wikitext again, which must give the same page
+func TestGoldenRoundTrip(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".md")
+		t.Run(name, func(t *testing.T) {
+			if reason, ok := noRoundTrip[name]; ok {
+				t.Skip(reason)
+			}
+			input, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wikitext := Convert(string(input), Config{}).Text
+			markdown := ToMarkdown(wikitext, Config{})
+			if again := Convert(markdown, Config{}).Text; again != wikitext {
+				t.Errorf("round trip changed the page:\n%s\nvia Markdown:\n%s", lineDiff(wikitext, again), markdown)
+			}
+		})
+	}
+}
+
+// lineDiff shows the lines that differ between want and got, marked with
+// - and + and numbered like the lines of want
+func lineDiff(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(&out, "%4d + %q\n", i+1, b[j])
+			j++
+		default:
+			fmt.Fprintf(&out, "%4d - %q\n", i+1, a[i])
+			i++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,747 @@
+package converter
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	tagNamePattern        = `[A-Za-z][A-Za-z0-9-]*`
+	attributeNamePattern  = `[a-zA-Z_:][a-zA-Z0-9:._-]*`
+	attributeValuePattern = `(?:[^"'=<>` + "`" + `\x00-\x20]+|'[^']*'|"[^"]*")`
+	attributePattern      = `(?:\s+` + attributeNamePattern + `(?:\s*=\s*` + attributeValuePattern + `)?)`
+	openTagPattern        = `<` + tagNamePattern + attributePattern + `*\s*/?>`
+	closeTagPattern       = `</` + tagNamePattern + `\s*>`
+	htmlCommentPattern    = `<!-->|<!--->|(?s:<!--.*?-->)`
+	processingPattern     = `(?s:<\?.*?\?>)`
+	declarationPattern    = `<![A-Za-z]+[^>]*>`
+	cdataPattern          = `(?s:<!\[CDATA\[.*?\]\]>)`
+)
+
+var (
+	htmlTagRegex = regexp.MustCompile(`^(?:` + openTagPattern + `|` + closeTagPattern + `|` + htmlCommentPattern + `|` +
+		processingPattern + `|` + declarationPattern + `|` + cdataPattern + `)`)
+	entityRegex         = regexp.MustCompile(`^&(?:#[xX][a-fA-F0-9]{1,6}|#[0-9]{1,7}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	autolinkRegex       = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*>`)
+	emailAutolinkRegex  = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	linkTitleRegex      = regexp.MustCompile(`^(?:"(?:\\.|[^"\\\x00])*"|'(?:\\.|[^'\\\x00])*'|\((?:\\.|[^()\\\x00])*\))`)
+	linkDestBracesRegex = regexp.MustCompile(`^<(?:[^<>\n\\\x00]|\\.)*>`)
+	backslashOrAmpRegex = regexp.MustCompile(`\\[!"#$%&'()*+,./:;<=>?@\[\\\]^_` + "`" + `{|}~-]|&(?:#[xX][a-fA-F0-9]{1,6}|#[0-9]{1,7}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+)
+
+// delimiter is an entry on the emphasis delimiter stack
+type delimiter struct {
+	char       byte
+	count      int
+	origCount  int
+	node       *Node
+	prev, next *delimiter
+	canOpen    bool
+	canClose   bool
+}
+
+// bracket is an entry on the link opener stack
+type bracket struct {
+	node          *Node
+	prev          *bracket
+	prevDelimiter *delimiter
+	index         int
+	image         bool
+	footnote      bool
+	active        bool
+	bracketAfter  bool
+}
+
+// inlineParser parses the inline content of a single block
+type inlineParser struct {
+	subject    string
+	pos        int
+	delimiters *delimiter
+	brackets   *bracket
+	refs       map[string]linkReference
+	footnotes  map[string]bool
+	features   feature
+}
+
+// parseInlines parses content and appends the resulting inline nodes to block
+func parseInlines(block *Node, content string, refs map[string]linkReference, footnotes map[string]bool, features feature) {
+	p := &inlineParser{subject: content, refs: refs, footnotes: footnotes, features: features}
+	for p.pos < len(p.subject) {
+		p.parseInline(block)
+	}
+	p.processEmphasis(nil)
+	mergeTextNodes(block)
+}
+
+func (p *inlineParser) hasFeature(f feature) bool {
+	return p.features&f != 0
+}
+
+func (p *inlineParser) peek() byte {
+	if p.pos < len(p.subject) {
+		return p.subject[p.pos]
+	}
+	return 0
+}
+
+func (p *inlineParser) match(re *regexp.Regexp) string {
+	m := re.FindString(p.subject[p.pos:])
+	p.pos += len(m)
+	return m
+}
+
+// spnl skips optional spaces, at most one newline, and more spaces
+func (p *inlineParser) spnl() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+	if p.peek() == '\n' {
+		p.pos++
+	}
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// parseInline parses one inline construct at the current position
+func (p *inlineParser) parseInline(block *Node) {
+	c := p.peek()
+	handled := false
+	switch c {
+	case '\n':
+		handled = p.parseNewline(block)
+	case '\\':
+		handled = p.parseBackslash(block)
+	case '`':
+		handled = p.hasFeature(featCode) && p.parseBackticks(block)
+	case '*', '_':
+		handled = p.hasFeature(featEmphasis) && p.handleDelim(c, block)
+	case '=', '~':
+		handled = p.hasFeature(featEmphasis) && p.handleDelim(c, block)
+	case '[':
+		handled = (p.hasFeature(featFootnotes) && p.parseFootnoteRef(block)) ||
+			(p.hasFeature(featLinks) && (p.parseWikiLink(block) || p.parseOpenBracket(block)))
+	case '^':
+		handled = p.hasFeature(featFootnotes) && p.parseCaret(block)
+	case '!':
+		handled = p.hasFeature(featLinks) && (p.parseEmbed(block) || p.parseBang(block))
+	case ']':
+		handled = p.hasFeature(featLinks) && p.parseCloseBracket(block)
+	case '<':
+		handled = p.parseAutolink(block) || p.parseHTMLTag(block)
+	case '&':
+		handled = p.parseEntity(block)
+	}
+	if !handled {
+		p.parseString(block)
+	}
+}
+
+// isSpecialChar reports whether c can start an inline construct
+func isSpecialChar(c byte) bool {
+	switch c {
+	case '\n', '\\', '`', '*', '_', '=', '~', '[', '!', '^', ']', '<', '&':
+		return true
+	}
+	return false
+}
+
+// parseString consumes a run of ordinary characters
+func (p *inlineParser) parseString(block *Node) {
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.subject) && !isSpecialChar(p.subject[p.pos]) {
+		p.pos++
+	}
+	block.AppendChild(newText(p.subject[start:p.pos]))
+}
+
+func (p *inlineParser) parseNewline(block *Node) bool {
+	p.pos++
+	last := block.LastChild
+	if last != nil && last.Kind == TextNode && strings.HasSuffix(last.Literal, " ") {
+		hard := strings.HasSuffix(last.Literal, "  ")
+		last.Literal = strings.TrimRight(last.Literal, " ")
+		if hard {
+			block.AppendChild(NewNode(HardBreakNode))
+		} else {
+			block.AppendChild(NewNode(SoftBreakNode))
+		}
+	} else {
+		block.AppendChild(NewNode(SoftBreakNode))
+	}
+	for p.peek() == ' ' {
+		p.pos++
+	}
+	return true
+}
+
+func isEscapable(c byte) bool {
+	return c != 0 && strings.IndexByte("!\"#$%&'()*+,./:;<=>?@[\\]^_`{|}~-", c) >= 0
+}
+
+func (p *inlineParser) parseBackslash(block *Node) bool {
+	p.pos++
+	switch {
+	case p.peek() == '\n':
+		p.pos++
+		block.AppendChild(NewNode(HardBreakNode))
+	case isEscapable(p.peek()):
+		block.AppendChild(newText(p.subject[p.pos : p.pos+1]))
+		p.pos++
+	default:
+		block.AppendChild(newText("\\"))
+	}
+	return true
+}
+
+func (p *inlineParser) parseBackticks(block *Node) bool {
+	start := p.pos
+	for p.peek() == '`' {
+		p.pos++
+	}
+	ticks := p.pos - start
+	afterOpen := p.pos
+	end := findBacktickRun(p.subject, afterOpen, ticks)
+	if end < 0 {
+		block.AppendChild(newText(p.subject[start:afterOpen]))
+		return true
+	}
+	p.pos = end + ticks
+	contents := strings.ReplaceAll(p.subject[afterOpen:end], "\n", " ")
+	if len(contents) > 2 && contents[0] == ' ' && contents[len(contents)-1] == ' ' && strings.Trim(contents, " ") != "" {
+		contents = contents[1 : len(contents)-1]
+	}
+	block.AppendChild(&Node{Kind: CodeSpanNode, Literal: contents})
+	return true
+}
+
+func (p *inlineParser) parseAutolink(block *Node) bool {
+	if m := emailAutolinkRegex.FindStringSubmatch(p.subject[p.pos:]); m != nil {
+		p.pos += len(m[0])
+		link := &Node{Kind: LinkNode, Destination: "mailto:" + m[1]}
+		link.AppendChild(newText(m[1]))
+		block.AppendChild(link)
+		return true
+	}
+	if m := autolinkRegex.FindString(p.subject[p.pos:]); m != "" {
+		p.pos += len(m)
+		dest := m[1 : len(m)-1]
+		link := &Node{Kind: LinkNode, Destination: dest}
+		link.AppendChild(newText(dest))
+		block.AppendChild(link)
+		return true
+	}
+	return false
+}
+
+func (p *inlineParser) parseHTMLTag(block *Node) bool {
+	if m := p.match(htmlTagRegex); m != "" {
+		block.AppendChild(&Node{Kind: HTMLInlineNode, Literal: m})
+		return true
+	}
+	return false
+}
+
+// parseEntity keeps entity references verbatim, since MediaWiki understands
+// them and decoding could produce characters that are markup
+func (p *inlineParser) parseEntity(block *Node) bool {
+	if m := p.match(entityRegex); m != "" {
+		block.AppendChild(&Node{Kind: HTMLInlineNode, Literal: m})
+		return true
+	}
+	return false
+}
+
+// scanDelims measures a delimiter run and whether it can open or close
+func (p *inlineParser) scanDelims(c byte) (count int, canOpen, canClose bool) {
+	start := p.pos
+	for p.pos+count < len(p.subject) && p.subject[p.pos+count] == c {
+		count++
+	}
+
+	before := '\n'
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.subject[:start])
+	}
+	after := '\n'
+	if start+count < len(p.subject) {
+		after, _ = utf8.DecodeRuneInString(p.subject[start+count:])
+	}
+
+	afterIsSpace := unicode.IsSpace(after)
+	afterIsPunct := isPunctuation(after)
+	beforeIsSpace := unicode.IsSpace(before)
+	beforeIsPunct := isPunctuation(before)
+
+	leftFlanking := !afterIsSpace && (!afterIsPunct || beforeIsSpace || beforeIsPunct)
+	rightFlanking := !beforeIsSpace && (!beforeIsPunct || afterIsSpace || afterIsPunct)
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || beforeIsPunct)
+		canClose = rightFlanking && (!leftFlanking || afterIsPunct)
+	} else {
+		canOpen = leftFlanking
+		canClose = rightFlanking
+	}
+	return count, canOpen, canClose
+}
+
+func isPunctuation(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// handleDelim pushes a run of emphasis, highlight or strikethrough
+// delimiters onto the stack as a text node
+func (p *inlineParser) handleDelim(c byte, block *Node) bool {
+	count, canOpen, canClose := p.scanDelims(c)
+	text := newText(p.subject[p.pos : p.pos+count])
+	p.pos += count
+	block.AppendChild(text)
+	if (c == '=' && count != 2) || (c == '~' && count > 2) {
+		return true
+	}
+	if canOpen || canClose {
+		d := &delimiter{
+			char:      c,
+			count:     count,
+			origCount: count,
+			node:      text,
+			prev:      p.delimiters,
+			canOpen:   canOpen,
+			canClose:  canClose,
+		}
+		if d.prev != nil {
+			d.prev.next = d
+		}
+		p.delimiters = d
+	}
+	return true
+}
+
+func (p *inlineParser) removeDelimiter(d *delimiter) {
+	if d.prev != nil {
+		d.prev.next = d.next
+	}
+	if d.next != nil {
+		d.next.prev = d.prev
+	} else {
+		p.delimiters = d.prev
+	}
+}
+
+// processEmphasis resolves delimiter runs above stackBottom into emphasis,
+// strong, highlight and strikethrough nodes
+func (p *inlineParser) processEmphasis(stackBottom *delimiter) {
+	openersBottom := make(map[[3]int]*delimiter)
+
+	closer := p.delimiters
+	for closer != nil && closer.prev != stackBottom {
+		closer = closer.prev
+	}
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		bottomKey := [3]int{int(closer.char), 0, closer.origCount % 3}
+		if closer.canOpen {
+			bottomKey[1] = 1
+		}
+		bottom, ok := openersBottom[bottomKey]
+		if !ok {
+			bottom = stackBottom
+		}
+
+		opener := closer.prev
+		found := false
+		for opener != nil && opener != stackBottom && opener != bottom {
+			oddMatch := (closer.canOpen || opener.canClose) && closer.origCount%3 != 0 && (opener.origCount+closer.origCount)%3 == 0
+			if opener.char == closer.char && opener.canOpen {
+				if closer.char == '*' || closer.char == '_' {
+					found = !oddMatch
+				} else {
+					found = opener.count == closer.count
+				}
+				if found {
+					break
+				}
+			}
+			opener = opener.prev
+		}
+
+		oldCloser := closer
+		if found {
+			used := 1
+			if closer.count >= 2 && opener.count >= 2 {
+				used = 2
+			}
+			kind := EmphasisNode
+			switch {
+			case closer.char == '=':
+				kind = HighlightNode
+			case closer.char == '~':
+				kind = StrikethroughNode
+				used = closer.count
+			case used == 2:
+				kind = StrongNode
+			}
+
+			openerNode := opener.node
+			closerNode := closer.node
+			opener.count -= used
+			closer.count -= used
+			openerNode.Literal = openerNode.Literal[:len(openerNode.Literal)-used]
+			closerNode.Literal = closerNode.Literal[:len(closerNode.Literal)-used]
+
+			emph := NewNode(kind)
+			for n := openerNode.Next; n != nil && n != closerNode; {
+				next := n.Next
+				emph.AppendChild(n)
+				n = next
+			}
+			openerNode.InsertAfter(emph)
+
+			// Delimiters between opener and closer can no longer match
+			opener.next = closer
+			closer.prev = opener
+
+			if opener.count == 0 {
+				openerNode.Unlink()
+				p.removeDelimiter(opener)
+			}
+			if closer.count == 0 {
+				closerNode.Unlink()
+				next := closer.next
+				p.removeDelimiter(closer)
+				closer = next
+			}
+		} else {
+			closer = closer.next
+			openersBottom[bottomKey] = oldCloser.prev
+			if !oldCloser.canOpen {
+				p.removeDelimiter(oldCloser)
+			}
+		}
+	}
+
+	for p.delimiters != nil && p.delimiters != stackBottom {
+		p.removeDelimiter(p.delimiters)
+	}
+}
+
+func (p *inlineParser) addBracket(node *Node, index int, image bool) {
+	if p.brackets != nil {
+		p.brackets.bracketAfter = true
+	}
+	p.brackets = &bracket{
+		node:          node,
+		prev:          p.brackets,
+		prevDelimiter: p.delimiters,
+		index:         index,
+		image:         image,
+		active:        true,
+	}
+}
+
+func (p *inlineParser) parseOpenBracket(block *Node) bool {
+	start := p.pos
+	p.pos++
+	node := newText("[")
+	block.AppendChild(node)
+	p.addBracket(node, start, false)
+	return true
+}
+
+func (p *inlineParser) parseBang(block *Node) bool {
+	start := p.pos
+	p.pos++
+	if p.peek() == '[' {
+		p.pos++
+		node := newText("![")
+		block.AppendChild(node)
+		p.addBracket(node, start+1, true)
+	} else {
+		block.AppendChild(newText("!"))
+	}
+	return true
+}
+
+// parseCloseBracket tries to match a link or image with the most recent
+// opening bracket
+func (p *inlineParser) parseCloseBracket(block *Node) bool {
+	p.pos++
+	start := p.pos
+
+	opener := p.brackets
+	if opener == nil {
+		block.AppendChild(newText("]"))
+		return true
+	}
+	if !opener.active {
+		block.AppendChild(newText("]"))
+		p.brackets = opener.prev
+		return true
+	}
+	if opener.footnote {
+		p.closeInlineFootnote(block, opener)
+		return true
+	}
+
+	var dest, title string
+	matched := false
+
+	// Inline link: [text](destination "title")
+	if p.peek() == '(' {
+		save := p.pos
+		p.pos++
+		p.spnl()
+		if d, ok := p.parseLinkDestination(); ok {
+			dest = d
+			beforeTitle := p.pos
+			p.spnl()
+			if p.pos > beforeTitle {
+				if t, ok := p.parseLinkTitle(); ok {
+					title = t
+				}
+			}
+			p.spnl()
+			if p.peek() == ')' {
+				p.pos++
+				matched = true
+			}
+		}
+		if !matched {
+			p.pos = save
+		}
+	}
+
+	// Reference link: [text][label], [text][] or [text]
+	if !matched {
+		beforeLabel := p.pos
+		n := p.parseLinkLabel()
+		var label string
+		if n > 2 {
+			label = p.subject[beforeLabel : beforeLabel+n]
+		} else if !opener.bracketAfter {
+			label = p.subject[opener.index:start]
+		}
+		if n == 0 {
+			p.pos = start
+		}
+		if label != "" {
+			if ref, ok := p.refs[normalizeReference(label)]; ok {
+				dest = ref.destination
+				title = ref.title
+				matched = true
+			}
+		}
+	}
+
+	if !matched {
+		p.brackets = opener.prev
+		p.pos = start
+		block.AppendChild(newText("]"))
+		return true
+	}
+
+	kind := LinkNode
+	if opener.image {
+		kind = ImageNode
+	}
+	link := &Node{Kind: kind, Destination: dest, Title: title}
+	for n := opener.node.Next; n != nil; {
+		next := n.Next
+		link.AppendChild(n)
+		n = next
+	}
+	block.AppendChild(link)
+	p.processEmphasis(opener.prevDelimiter)
+	p.brackets = opener.prev
+	opener.node.Unlink()
+
+	// Links may not contain other links
+	if !opener.image {
+		for b := p.brackets; b != nil; b = b.prev {
+			if !b.image && !b.footnote {
+				b.active = false
+			}
+		}
+	}
+	return true
+}
+
+// parseLinkDestination parses a link destination in angle brackets or bare
+func (p *inlineParser) parseLinkDestination() (string, bool) {
+	if m := p.match(linkDestBracesRegex); m != "" {
+		return unescapeString(m[1 : len(m)-1]), true
+	}
+	if p.peek() == '<' {
+		return "", false
+	}
+	start := p.pos
+	openParens := 0
+	for p.pos < len(p.subject) {
+		c := p.subject[p.pos]
+		if c == '\\' && p.pos+1 < len(p.subject) && isEscapable(p.subject[p.pos+1]) {
+			p.pos += 2
+		} else if c == '(' {
+			p.pos++
+			openParens++
+		} else if c == ')' {
+			if openParens < 1 {
+				break
+			}
+			p.pos++
+			openParens--
+		} else if c <= ' ' {
+			break
+		} else {
+			p.pos++
+		}
+	}
+	if p.pos == start && p.peek() != ')' {
+		return "", false
+	}
+	if openParens != 0 {
+		return "", false
+	}
+	return unescapeString(p.subject[start:p.pos]), true
+}
+
+func (p *inlineParser) parseLinkTitle() (string, bool) {
+	m := p.match(linkTitleRegex)
+	if m == "" {
+		return "", false
+	}
+	return unescapeString(m[1 : len(m)-1]), true
+}
+
+// parseLinkLabel returns the length of a link label at the current
+// position, or 0 if there is none
+func (p *inlineParser) parseLinkLabel() int {
+	if p.peek() != '[' {
+		return 0
+	}
+	for i := p.pos + 1; i < len(p.subject) && i-p.pos <= 1000; i++ {
+		switch p.subject[i] {
+		case '\\':
+			i++
+		case '[':
+			return 0
+		case ']':
+			n := i + 1 - p.pos
+			p.pos = i + 1
+			return n
+		}
+	}
+	return 0
+}
+
+// parseReference parses a link reference definition at the start of s and
+// returns the number of bytes consumed, or 0
+func parseReference(s string, refs map[string]linkReference) int {
+	p := &inlineParser{subject: s}
+	n := p.parseLinkLabel()
+	if n == 0 {
+		return 0
+	}
+	rawLabel := s[:n]
+	if p.peek() != ':' {
+		return 0
+	}
+	p.pos++
+	p.spnl()
+	dest, ok := p.parseLinkDestination()
+	if !ok {
+		return 0
+	}
+	beforeTitle := p.pos
+	p.spnl()
+	title := ""
+	hasTitle := false
+	if p.pos != beforeTitle {
+		title, hasTitle = p.parseLinkTitle()
+	}
+	if !hasTitle {
+		p.pos = beforeTitle
+	}
+	if !p.skipToLineEnd() {
+		// A title that isn't followed by the line end is not part of the
+		// definition, but the destination alone may still be
+		if !hasTitle {
+			return 0
+		}
+		title = ""
+		p.pos = beforeTitle
+		if !p.skipToLineEnd() {
+			return 0
+		}
+	}
+	label := normalizeReference(rawLabel)
+	if label == "" {
+		return 0
+	}
+	if _, exists := refs[label]; !exists {
+		refs[label] = linkReference{destination: dest, title: title}
+	}
+	return p.pos
+}
+
+// skipToLineEnd consumes trailing spaces and the newline, reporting false
+// and leaving the position unchanged if other text follows
+func (p *inlineParser) skipToLineEnd() bool {
+	pos := p.pos
+	for pos < len(p.subject) && p.subject[pos] == ' ' {
+		pos++
+	}
+	if pos < len(p.subject) && p.subject[pos] != '\n' {
+		return false
+	}
+	if pos < len(p.subject) {
+		pos++
+	}
+	p.pos = pos
+	return true
+}
+
+// normalizeReference case-folds a link label and collapses its whitespace
+func normalizeReference(label string) string {
+	label = strings.TrimSpace(label[1 : len(label)-1])
+	return strings.ToUpper(strings.ToLower(strings.Join(strings.Fields(label), " ")))
+}
+
+// unescapeString resolves backslash escapes and entity references
+func unescapeString(s string) string {
+	if !strings.ContainsAny(s, `\&`) {
+		return s
+	}
+	return backslashOrAmpRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if m[0] == '\\' {
+			return m[1:]
+		}
+		return html.UnescapeString(m)
+	})
+}
+
+// mergeTextNodes joins adjacent text nodes left over from delimiter handling
+func mergeTextNodes(parent *Node) {
+	for n := parent.FirstChild; n != nil; {
+		if n.Kind == TextNode {
+			for n.Next != nil && n.Next.Kind == TextNode {
+				n.Literal += n.Next.Literal
+				n.Next.Unlink()
+			}
+		} else {
+			mergeTextNodes(n)
+		}
+		next := n.Next
+		if n.Kind == TextNode && n.Literal == "" {
+			n.Unlink()
+		}
+		n = next
+	}
+}
@@ -0,0 +1,215 @@
+package converter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lint codes for the pitfalls of the README's Best Practices section
+const (
+	CodeBareUnderscores = "bare-underscores" // An identifier whose underscores are read as emphasis
+	CodeSectionSpacing  = "section-spacing"  // A section heading without a --- rule before it
+)
+
+var (
+	// Identifier-like tokens such as KOBO_MELDINGSDIALOG_VEDLEGG or
+	// _private_name_, with underscores between their words
+	underscoreTokenRegex = regexp.MustCompile(`_*[A-Za-z0-9]+(?:_+[A-Za-z0-9]+)+_*`)
+
+	// Spans where underscores are not text: URLs, wikilinks, link
+	// destinations and HTML tags
+	lintSkipRegex = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^\s)>\]]*|\[\[[^\]]*\]\]|\]\([^)]*\)|<[^>\s][^>]*>|\S+@\S+\.\w+`)
+)
+
+// lintEdit replaces the bytes start to end of a source line
+type lintEdit struct {
+	line       int // 1-based
+	start, end int
+	text       string
+}
+
+// lintResult holds the issues found in a document and the edits fixing them
+type lintResult struct {
+	diagnostics []Diagnostic
+	edits       []lintEdit
+}
+
+// Lint reports the Markdown patterns that turn out badly in MediaWiki:
+// identifiers whose underscores become italics, bullet lists nested in
+// numbered items and major sections not separated by a --- rule. Each
+// diagnostic carries a suggested fix.
+func Lint(markdown string) []Diagnostic {
+	return lint(markdown).diagnostics
+}
+
+// FixLint rewrites the Markdown to fix the issues Lint can fix by itself
+// and returns the new source with the issues that are left. Every line
+// keeps its line ending, and lines added before a line take its ending.
+func FixLint(markdown string) (string, []Diagnostic) {
+	result := lint(markdown)
+	if len(result.edits) == 0 {
+		return markdown, result.diagnostics
+	}
+
+	lines := strings.Split(markdown, "\n")
+	edits := result.edits
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].line != edits[j].line {
+			return edits[i].line > edits[j].line
+		}
+		return edits[i].start > edits[j].start
+	})
+	for _, e := range edits {
+		line := lines[e.line-1]
+		text := e.text
+		if strings.HasSuffix(line, "\r") {
+			text = strings.ReplaceAll(text, "\n", "\r\n")
+		}
+		lines[e.line-1] = line[:e.start] + text + line[e.end:]
+	}
+	fixed := strings.Join(lines, "\n")
+	return fixed, lint(fixed).diagnostics
+}
+
+func lint(markdown string) lintResult {
+	doc, diagnostics := ParseWithDiagnostics(markdown)
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	var result lintResult
+
+	for _, d := range diagnostics {
+		if d.Code == CodeListNesting {
+			d.Suggestion = "use bold headers with manual numbering, e.g. **1. First Item**, instead of a numbered list"
+			result.diagnostics = append(result.diagnostics, d)
+		}
+	}
+
+	verbatim := verbatimLines(doc)
+	for i, line := range lines {
+		if !verbatim[i+1] {
+			lintUnderscores(i+1, line, &result)
+		}
+	}
+
+	for n := doc.FirstChild; n != nil; n = n.Next {
+		if !needsSectionRule(n) {
+			continue
+		}
+		rule := "---\n\n"
+		if n.Line >= 2 && strings.TrimSpace(lines[n.Line-2]) != "" {
+			rule = "\n" + rule
+		}
+		result.diagnostics = append(result.diagnostics, Diagnostic{
+			Line:       n.Line,
+			Column:     1,
+			Severity:   SeverityWarning,
+			Code:       CodeSectionSpacing,
+			Message:    "section runs into the previous one without a horizontal rule",
+			Suggestion: "add a --- line before the heading",
+		})
+		result.edits = append(result.edits, lintEdit{line: n.Line, text: rule})
+	}
+
+	sortDiagnostics(result.diagnostics)
+	return result
+}
+
+// verbatimLines returns the source lines of code blocks, HTML blocks and
+// front matter, where Markdown is not interpreted
+func verbatimLines(doc *Node) map[int]bool {
+	lines := make(map[int]bool)
+	Walk(doc, func(n *Node, entering bool) WalkStatus {
+		if !entering {
+			return WalkContinue
+		}
+		var count int
+		switch n.Kind {
+		case CodeBlockNode:
+			count = strings.Count(n.Literal, "\n")
+			if n.Fenced {
+				count += 2 // The fences
+			}
+		case HTMLBlockNode:
+			count = strings.Count(n.Literal, "\n") + 1
+		case FrontMatterNode:
+			count = strings.Count(n.Literal, "\n") + 3
+		default:
+			return WalkContinue
+		}
+		for l := n.Line; l < n.Line+count; l++ {
+			lines[l] = true
+		}
+		return WalkSkipChildren
+	})
+	return lines
+}
+
+// lintUnderscores reports the underscored identifiers of a line that are
+// outside code spans, links and HTML and whose underscores the parser
+// reads as emphasis. Underscores inside a word never are.
+func lintUnderscores(lineNumber int, line string, result *lintResult) {
+	skip := make([]bool, len(line))
+	for _, m := range lintSkipRegex.FindAllStringIndex(line, -1) {
+		for i := m[0]; i < m[1]; i++ {
+			skip[i] = true
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] != '`' {
+			continue
+		}
+		run := 1
+		for i+run < len(line) && line[i+run] == '`' {
+			run++
+		}
+		end := findBacktickRun(line, i+run, run)
+		if end < 0 {
+			i += run - 1
+			continue
+		}
+		for j := i; j < end+run; j++ {
+			skip[j] = true
+		}
+		i = end + run - 1
+	}
+
+	emphasis := -1
+	for _, m := range underscoreTokenRegex.FindAllStringIndex(line, -1) {
+		if skip[m[0]] || skip[m[1]-1] {
+			continue
+		}
+		token := line[m[0]:m[1]]
+		if emphasis < 0 {
+			emphasis = emphasisCount(line)
+		}
+		if emphasisCount(line[:m[0]]+strings.ReplaceAll(token, "_", "x")+line[m[1]:]) == emphasis {
+			continue
+		}
+		result.diagnostics = append(result.diagnostics, Diagnostic{
+			Line:       lineNumber,
+			Column:     m[0] + 1,
+			Severity:   SeverityWarning,
+			Code:       CodeBareUnderscores,
+			Message:    "underscores in " + token + " are read as italics",
+			Suggestion: "wrap it in backticks: `" + token + "`",
+		})
+		result.edits = append(result.edits, lintEdit{line: lineNumber, start: m[0], end: m[1], text: "`" + token + "`"})
+	}
+}
+
+// emphasisCount counts the emphasis and strong spans the parser reads in a
+// line
+func emphasisCount(line string) int {
+	count := 0
+	Walk(Parse(line), func(n *Node, entering bool) WalkStatus {
+		if entering && (n.Kind == EmphasisNode || n.Kind == StrongNode) {
+			count++
+		}
+		return WalkContinue
+	})
+	return count
+}
@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Underscores Read As Emphasis",
+			input:    "Set _private_name_ and __dunder_name__",
+			expected: []string{"1:5: warning: underscores in _private_name_ are read as italics [bare-underscores]", "1:24: warning: underscores in __dunder_name__ are read as italics [bare-underscores]"},
+		},
+		{
+			name:     "Intraword Underscores",
+			input:    "Set KOBO_MELDINGSDIALOG_VEDLEGG, snake_case_name, max__retries and _leading_name",
+			expected: nil,
+		},
+		{
+			name:     "Underscores Outside Text",
+			input:    "---\nkey: A_B\n---\nUse `A_B`, _emphasis_, [x](docs/a_b.md), [[Page_Name]], https://x.org/a_b, <span id=\"a_b\">x</span>\n\n```\nA_B\n```\n\n    A_B\n\n<div>\nA_B\n</div>",
+			expected: nil,
+		},
+		{
+			name:     "Nested Bullets",
+			input:    "1. First\n   - nested",
+			expected: []string{"2:4: warning: bullet list nested in a numbered item; MediaWiki may render it with double bullets [list-nesting]"},
+		},
+		{
+			name:     "Section Spacing",
+			input:    "# Title\n\n## Intro\n\nText\n\n---\n\n## Setup\n\nText\n\n## Usage\n\n### Details\n\n> ## Quoted",
+			expected: []string{"13:1: warning: section runs into the previous one without a horizontal rule [section-spacing]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range Lint(tt.input) {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Lint() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFixLint(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		remaining int
+	}{
+		{
+			name:     "Wraps Identifiers",
+			input:    "Set _A_B_ and _C_D_, not `_E_F_` or G_H\n",
+			expected: "Set `_A_B_` and `_C_D_`, not `_E_F_` or G_H\n",
+		},
+		{
+			name:     "Adds Rules",
+			input:    "Intro\n## One\nText\n\n## Two\n",
+			expected: "Intro\n\n---\n\n## One\nText\n\n---\n\n## Two\n",
+		},
+		{
+			name:     "Setext Heading",
+			input:    "Intro\n\nOne\n---\n",
+			expected: "Intro\n\n---\n\nOne\n---\n",
+		},
+		{
+			name:     "Keeps CRLF",
+			input:    "Intro\r\n## One\r\nSet _A_B_\r\n",
+			expected: "Intro\r\n\r\n---\r\n\r\n## One\r\nSet `_A_B_`\r\n",
+		},
+		{
+			name:     "Keeps Mixed Line Endings",
+			input:    "Intro\n## One\r\nText\r\n## Two\nSet _A_B_\r\n",
+			expected: "Intro\n\r\n---\r\n\r\n## One\r\nText\r\n\n---\n\n## Two\nSet `_A_B_`\r\n",
+		},
+		{
+			name:      "Nested Bullets Are Left",
+			input:     "1. First\n   - _A_B_\n",
+			expected:  "1. First\n   - `_A_B_`\n",
+			remaining: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, remaining := FixLint(tt.input)
+			if got != tt.expected {
+				t.Errorf("FixLint() = %q, want %q", got, tt.expected)
+			}
+			if len(remaining) != tt.remaining {
+				t.Errorf("FixLint() left %v, want %d issue(s)", remaining, tt.remaining)
+			}
+		})
+	}
+}
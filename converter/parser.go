@@ -0,0 +1,1106 @@
+package converter
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The block parser follows the CommonMark reference algorithm: every input
+// line is matched against the chain of open container blocks, new block
+// starts are tried on whatever is left, and the remainder is added as text
+// to the innermost block that accepts lines. Inline content is parsed in a
+// second phase once all link reference definitions are known.
+
+const codeIndent = 4
+
+// feature selects which Markdown constructs are recognised. The legacy
+// Convert* wrappers use it to convert a single construct in isolation.
+type feature uint
+
+const (
+	featHeadings feature = 1 << iota
+	featCode
+	featEmphasis
+	featLinks
+	featCallouts
+	featLists
+	featTables
+	featRules
+	featFrontMatter
+	featFootnotes
+	featDefinitions
+
+	featAll = featHeadings | featCode | featEmphasis | featLinks | featCallouts | featLists | featTables | featRules | featFrontMatter | featFootnotes | featDefinitions
+)
+
+// blockState holds parser bookkeeping for a block while it is open
+type blockState struct {
+	open          bool
+	lastLineBlank bool
+	content       strings.Builder
+
+	fenceChar     byte
+	fenceLength   int
+	fenceOffset   int
+	fenceColumn   int
+	fenceClosed   bool
+	htmlBlockType int
+	list          listData
+	align         []string // Column alignments of a table
+}
+
+// listData describes the marker of a list or list item
+type listData struct {
+	ordered      bool
+	bulletChar   byte
+	delimiter    byte
+	start        int
+	markerOffset int
+	padding      int
+}
+
+// linkReference is a resolved link reference definition
+type linkReference struct {
+	destination string
+	title       string
+}
+
+var (
+	atxHeadingRegex     = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	atxClosingRegex     = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+	setextHeadingRegex  = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+	thematicBreakRegex  = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:_[ \t]*){3,}|(?:-[ \t]*){3,})$`)
+	bulletMarkerRegex   = regexp.MustCompile(`^[*+-]`)
+	orderedMarkerRegex  = regexp.MustCompile(`^(\d{1,9})([.)])`)
+	definitionRegex     = regexp.MustCompile(`^:[ \t]+\S`)
+	calloutMarkerRegex  = regexp.MustCompile(`^\[!([A-Za-z][A-Za-z0-9_-]*)\]([+-]?)(?:[ \t]+([^\n]*))?(?:\n|$)`)
+	tableDelimiterRegex = regexp.MustCompile(`^\|?(?:[ \t]*:?-+:?[ \t]*\|)*[ \t]*:?-+:?[ \t]*\|?[ \t]*$`)
+)
+
+// HTML block start conditions 1-7 from the CommonMark spec. Condition 1 is
+// extended with the MediaWiki extension tags whose content must be kept
+// verbatim even across blank lines.
+var (
+	htmlBlockOpen = []*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`(?i)^<(?:script|pre|textarea|style|syntaxhighlight|source|nowiki|math)(?:\s|>|$)`),
+		regexp.MustCompile(`^<!--`),
+		regexp.MustCompile(`^<[?]`),
+		regexp.MustCompile(`^<![A-Za-z]`),
+		regexp.MustCompile(`^<!\[CDATA\[`),
+		regexp.MustCompile(`(?i)^</?(?:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[123456]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:\s|/?>|$)`),
+		regexp.MustCompile(`(?i)^(?:` + openTagPattern + `|` + closeTagPattern + `)\s*$`),
+	}
+	htmlBlockClose = []*regexp.Regexp{
+		nil,
+		regexp.MustCompile(`(?i)</(?:script|pre|textarea|style|syntaxhighlight|source|nowiki|math)>`),
+		regexp.MustCompile(`-->`),
+		regexp.MustCompile(`\?>`),
+		regexp.MustCompile(`>`),
+		regexp.MustCompile(`\]\]>`),
+	}
+)
+
+// blockParser turns Markdown source into a document tree
+type blockParser struct {
+	doc      *Node
+	tip      *Node
+	oldTip   *Node
+	features feature
+
+	line                 string
+	lineNumber           int
+	offset               int
+	column               int
+	nextNonspace         int
+	nextNonspaceColumn   int
+	indent               int
+	indented             bool
+	blank                bool
+	partiallyConsumedTab bool
+	allClosed            bool
+	lastMatchedContainer *Node
+
+	refs      map[string]linkReference
+	footnotes map[string]bool // keys of defined footnote labels
+
+	source      []string // Source lines, for diagnostic columns
+	diagnostics []Diagnostic
+}
+
+// Parse parses Markdown source into a document tree
+func Parse(markdown string) *Node {
+	doc, _ := parse(markdown, featAll)
+	return doc
+}
+
+// ParseWithDiagnostics parses Markdown source into a document tree and
+// reports the constructs that will not convert cleanly
+func ParseWithDiagnostics(markdown string) (*Node, []Diagnostic) {
+	return parse(markdown, featAll)
+}
+
+// parse parses Markdown source recognising only the given features
+func parse(markdown string, features feature) (*Node, []Diagnostic) {
+	p := &blockParser{
+		features:  features,
+		refs:      make(map[string]linkReference),
+		footnotes: make(map[string]bool),
+	}
+	p.doc = p.newBlock(DocumentNode, 0)
+	p.doc.Line = 1
+	p.tip = p.doc
+
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.ReplaceAll(markdown, "\x00", "�")
+	lines := strings.Split(markdown, "\n")
+	if strings.HasSuffix(markdown, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+	p.source = lines
+	if p.hasFeature(featFrontMatter) {
+		lines = p.parseFrontMatter(lines)
+	}
+	for _, line := range lines {
+		p.incorporateLine(line)
+	}
+	for p.tip != nil {
+		p.finalize(p.tip)
+	}
+
+	p.processInlines()
+	p.checkInlineHTML()
+	sortDiagnostics(p.diagnostics)
+	return p.doc, p.diagnostics
+}
+
+// parseFrontMatter takes a YAML front matter block delimited by "---" lines
+// off the start of the document and returns the remaining lines. A block
+// that is not a YAML mapping is left to be read as Markdown, where the
+// "---" lines are rules.
+func (p *blockParser) parseFrontMatter(lines []string) []string {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if delim := strings.TrimRight(lines[i], " \t"); delim != "---" && delim != "..." {
+			continue
+		}
+		literal := strings.Join(lines[1:i], "\n")
+		fields, err := parseFrontMatter(literal)
+		if err != nil && !errors.Is(err, errNotMapping) {
+			p.warn(1, 1, CodeFrontMatter, "front matter is not valid YAML, read as Markdown: %v", err)
+		}
+		if err != nil || (fields == nil && i > 1) {
+			return lines
+		}
+		p.doc.AppendChild(&Node{Kind: FrontMatterNode, Line: 1, Literal: literal})
+		p.lineNumber = i + 1
+		return lines[i+1:]
+	}
+	return lines
+}
+
+func (p *blockParser) newBlock(kind NodeKind, line int) *Node {
+	return &Node{Kind: kind, Line: line, block: &blockState{open: true}}
+}
+
+func (p *blockParser) hasFeature(f feature) bool {
+	return p.features&f != 0
+}
+
+func (p *blockParser) peek(pos int) byte {
+	if pos < len(p.line) {
+		return p.line[pos]
+	}
+	return 0
+}
+
+func isSpaceOrTab(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func (p *blockParser) findNextNonspace() {
+	i := p.offset
+	cols := p.column
+	for i < len(p.line) {
+		c := p.line[i]
+		if c == ' ' {
+			i++
+			cols++
+		} else if c == '\t' {
+			i++
+			cols += 4 - (cols % 4)
+		} else {
+			break
+		}
+	}
+	p.blank = i >= len(p.line)
+	p.nextNonspace = i
+	p.nextNonspaceColumn = cols
+	p.indent = p.nextNonspaceColumn - p.column
+	p.indented = p.indent >= codeIndent
+}
+
+func (p *blockParser) advanceNextNonspace() {
+	p.offset = p.nextNonspace
+	p.column = p.nextNonspaceColumn
+	p.partiallyConsumedTab = false
+}
+
+// advanceOffset moves forward count characters, or count columns when
+// columns is true, splitting tabs where necessary
+func (p *blockParser) advanceOffset(count int, columns bool) {
+	for count > 0 && p.offset < len(p.line) {
+		if p.line[p.offset] == '\t' {
+			charsToTab := 4 - (p.column % 4)
+			if columns {
+				p.partiallyConsumedTab = charsToTab > count
+				charsToAdvance := charsToTab
+				if charsToAdvance > count {
+					charsToAdvance = count
+				}
+				p.column += charsToAdvance
+				if !p.partiallyConsumedTab {
+					p.offset++
+				}
+				count -= charsToAdvance
+			} else {
+				p.partiallyConsumedTab = false
+				p.column += charsToTab
+				p.offset++
+				count--
+			}
+		} else {
+			p.partiallyConsumedTab = false
+			p.offset++
+			p.column++
+			count--
+		}
+	}
+}
+
+// addLine appends the rest of the current line to the tip's content
+func (p *blockParser) addLine() {
+	if p.partiallyConsumedTab {
+		p.offset++
+		charsToTab := 4 - (p.column % 4)
+		p.tip.block.content.WriteString(strings.Repeat(" ", charsToTab))
+	}
+	p.tip.block.content.WriteString(p.line[p.offset:])
+	p.tip.block.content.WriteByte('\n')
+}
+
+// addChild adds a new block as a child of the tip, closing blocks that
+// cannot contain it
+func (p *blockParser) addChild(kind NodeKind) *Node {
+	for !canContain(p.tip.Kind, kind) {
+		p.finalize(p.tip)
+	}
+	child := p.newBlock(kind, p.lineNumber)
+	p.tip.AppendChild(child)
+	p.tip = child
+	return child
+}
+
+func canContain(parent, child NodeKind) bool {
+	switch parent {
+	case DocumentNode, BlockquoteNode, ListItemNode, FootnoteDefinitionNode, DefinitionNode:
+		return child != ListItemNode && child != DefinitionTermNode && child != DefinitionNode
+	case ListNode:
+		return child == ListItemNode
+	case DefinitionListNode:
+		return child == DefinitionTermNode || child == DefinitionNode
+	}
+	return false
+}
+
+func acceptsLines(kind NodeKind) bool {
+	switch kind {
+	case ParagraphNode, CodeBlockNode, HTMLBlockNode, TableNode:
+		return true
+	}
+	return false
+}
+
+func (p *blockParser) closeUnmatchedBlocks() {
+	if !p.allClosed {
+		for p.oldTip != p.lastMatchedContainer {
+			parent := p.oldTip.Parent
+			p.finalize(p.oldTip)
+			p.oldTip = parent
+		}
+		p.allClosed = true
+	}
+}
+
+// continueBlock reports whether the open block matches the current line:
+// 0 = matched, 1 = not matched, 2 = matched and the line is fully consumed
+func (p *blockParser) continueBlock(container *Node) int {
+	switch container.Kind {
+	case BlockquoteNode:
+		if !p.indented && p.peek(p.nextNonspace) == '>' {
+			p.advanceNextNonspace()
+			p.advanceOffset(1, false)
+			if isSpaceOrTab(p.peek(p.offset)) {
+				p.advanceOffset(1, true)
+			}
+			return 0
+		}
+		return 1
+	case ListItemNode, DefinitionNode:
+		data := container.block.list
+		if p.blank {
+			if container.FirstChild == nil {
+				return 1
+			}
+			p.advanceNextNonspace()
+		} else if p.indent >= data.markerOffset+data.padding {
+			p.advanceOffset(data.markerOffset+data.padding, true)
+		} else {
+			return 1
+		}
+		return 0
+	case FootnoteDefinitionNode:
+		// Definitions continue with lines indented by four spaces
+		if p.blank {
+			p.advanceNextNonspace()
+		} else if p.indent >= codeIndent {
+			p.advanceOffset(codeIndent, true)
+		} else {
+			return 1
+		}
+		return 0
+	case HeadingNode, ThematicBreakNode, DefinitionTermNode:
+		return 1
+	case CodeBlockNode:
+		if container.Fenced {
+			rest := p.line[p.nextNonspace:]
+			if p.indent <= 3 && p.peek(p.nextNonspace) == container.block.fenceChar {
+				if n := closingFenceLength(rest, container.block.fenceChar); n >= container.block.fenceLength {
+					container.block.fenceClosed = true
+					p.finalize(container)
+					return 2
+				}
+			}
+			for i := container.block.fenceOffset; i > 0 && isSpaceOrTab(p.peek(p.offset)); i-- {
+				p.advanceOffset(1, true)
+			}
+			return 0
+		}
+		if p.indent >= codeIndent {
+			p.advanceOffset(codeIndent, true)
+		} else if p.blank {
+			p.advanceNextNonspace()
+		} else {
+			return 1
+		}
+		return 0
+	case HTMLBlockNode:
+		t := container.block.htmlBlockType
+		if p.blank && (t == 6 || t == 7) {
+			return 1
+		}
+		return 0
+	case ParagraphNode:
+		if p.blank {
+			return 1
+		}
+		return 0
+	case TableNode:
+		rest := p.line[p.nextNonspace:]
+		if p.blank || !strings.Contains(rest, "|") || (!p.indented && startsBlock(rest)) {
+			return 1
+		}
+		return 0
+	}
+	return 0
+}
+
+// startsBlock reports whether a line starts a heading, block quote, fence,
+// list item, thematic break or HTML block, which end a table
+func startsBlock(s string) bool {
+	if atxHeadingRegex.MatchString(s) || strings.HasPrefix(s, ">") || thematicBreakRegex.MatchString(s) {
+		return true
+	}
+	if c, _ := openingFence(s); c != 0 {
+		return true
+	}
+	marker := bulletMarkerRegex.FindString(s)
+	if m := orderedMarkerRegex.FindString(s); m != "" {
+		marker = m
+	}
+	if marker != "" && (len(s) == len(marker) || isSpaceOrTab(s[len(marker)])) {
+		return true
+	}
+	for t := 1; t <= 6; t++ {
+		if htmlBlockOpen[t].MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// closingFenceLength returns the length of a closing code fence made of c,
+// or 0 if s is not a closing fence
+func closingFenceLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	if n < 3 || strings.TrimRight(s[n:], " \t") != "" {
+		return 0
+	}
+	return n
+}
+
+// openingFence returns the fence character and length if s starts a fenced
+// code block
+func openingFence(s string) (byte, int) {
+	if len(s) == 0 || (s[0] != '`' && s[0] != '~') {
+		return 0, 0
+	}
+	c := s[0]
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	if n < 3 {
+		return 0, 0
+	}
+	if c == '`' && strings.Contains(s[n:], "`") {
+		return 0, 0
+	}
+	return c, n
+}
+
+// tryBlockStart attempts each block start in order: 0 = no match,
+// 1 = matched a container, 2 = matched a leaf
+func (p *blockParser) tryBlockStart(container *Node) int {
+	rest := p.line[p.nextNonspace:]
+
+	// Block quote
+	if !p.indented && p.peek(p.nextNonspace) == '>' {
+		p.advanceNextNonspace()
+		p.advanceOffset(1, false)
+		if isSpaceOrTab(p.peek(p.offset)) {
+			p.advanceOffset(1, true)
+		}
+		p.closeUnmatchedBlocks()
+		p.addChild(BlockquoteNode)
+		return 1
+	}
+
+	// Footnote definition, which may interrupt a paragraph so consecutive
+	// definitions don't run together
+	if !p.indented && p.hasFeature(featFootnotes) {
+		if m := footnoteDefinitionRegex.FindStringSubmatch(rest); m != nil {
+			p.advanceNextNonspace()
+			p.advanceOffset(len(m[0]), false)
+			p.closeUnmatchedBlocks()
+			def := p.addChild(FootnoteDefinitionNode)
+			def.Label = m[1]
+			p.footnotes[footnoteKey(def.Label)] = true
+			return 1
+		}
+	}
+
+	// ATX heading
+	if !p.indented && p.hasFeature(featHeadings) {
+		if m := atxHeadingRegex.FindString(rest); m != "" {
+			p.advanceNextNonspace()
+			p.advanceOffset(len(m), false)
+			p.closeUnmatchedBlocks()
+			heading := p.addChild(HeadingNode)
+			heading.Level = len(strings.TrimRight(m, " \t"))
+			content := atxClosingRegex.ReplaceAllString(p.line[p.offset:], "")
+			heading.block.content.WriteString(content)
+			p.advanceOffset(len(p.line)-p.offset, false)
+			return 2
+		}
+	}
+
+	// Fenced code block
+	if !p.indented && p.hasFeature(featCode) {
+		if c, n := openingFence(rest); n > 0 {
+			p.closeUnmatchedBlocks()
+			code := p.addChild(CodeBlockNode)
+			code.Fenced = true
+			code.block.fenceChar = c
+			code.block.fenceLength = n
+			code.block.fenceOffset = p.indent
+			code.block.fenceColumn = p.nextNonspaceColumn + 1
+			p.advanceNextNonspace()
+			p.advanceOffset(n, false)
+			return 2
+		}
+	}
+
+	// HTML block
+	if !p.indented && p.peek(p.nextNonspace) == '<' {
+		for t := 1; t <= 7; t++ {
+			if htmlBlockOpen[t].MatchString(rest) && (t < 7 || container.Kind != ParagraphNode) {
+				p.closeUnmatchedBlocks()
+				html := p.addChild(HTMLBlockNode)
+				html.block.htmlBlockType = t
+				return 2
+			}
+		}
+	}
+
+	// Setext heading
+	if !p.indented && container.Kind == ParagraphNode && p.hasFeature(featHeadings) && setextHeadingRegex.MatchString(rest) {
+		p.closeUnmatchedBlocks()
+		content := p.consumeReferences(container.block.content.String())
+		if content != "" {
+			heading := p.newBlock(HeadingNode, container.Line)
+			if rest[0] == '=' {
+				heading.Level = 1
+			} else {
+				heading.Level = 2
+			}
+			heading.block.content.WriteString(content)
+			container.InsertAfter(heading)
+			container.Unlink()
+			p.tip = heading
+			p.advanceOffset(len(p.line)-p.offset, false)
+			return 2
+		}
+	}
+
+	// Table, recognised when a delimiter row follows a header row
+	if !p.indented && container.Kind == ParagraphNode && p.hasFeature(featTables) {
+		if table := p.tryTableStart(container, rest); table {
+			return 2
+		}
+	}
+
+	// Thematic break
+	if !p.indented && p.hasFeature(featRules) && thematicBreakRegex.MatchString(rest) {
+		p.closeUnmatchedBlocks()
+		p.addChild(ThematicBreakNode)
+		p.advanceOffset(len(p.line)-p.offset, false)
+		return 2
+	}
+
+	// List item
+	if (!p.indented || container.Kind == ListNode) && p.hasFeature(featLists) {
+		if data, ok := p.parseListMarker(container); ok {
+			p.closeUnmatchedBlocks()
+			if p.tip.Kind != ListNode || !listsMatch(container.block.list, data) {
+				list := p.addChild(ListNode)
+				list.block.list = data
+				list.Ordered = data.ordered
+				list.Start = data.start
+			}
+			item := p.addChild(ListItemNode)
+			item.block.list = data
+			return 1
+		}
+	}
+
+	// Definition, after the paragraph holding its terms or after an earlier
+	// definition of the same terms
+	if !p.indented && p.hasFeature(featDefinitions) && definitionRegex.MatchString(rest) &&
+		(container.Kind == ParagraphNode || container.Kind == DefinitionListNode) {
+		p.closeUnmatchedBlocks()
+		if container.Kind == ParagraphNode {
+			p.startDefinitionList(container)
+		}
+		data := p.parseDefinitionMarker()
+		def := p.addChild(DefinitionNode)
+		def.block.list = data
+		return 1
+	}
+
+	// Indented code block
+	if p.indented && p.tip.Kind != ParagraphNode && !p.blank && p.hasFeature(featCode) {
+		p.advanceOffset(codeIndent, true)
+		p.closeUnmatchedBlocks()
+		p.addChild(CodeBlockNode)
+		return 2
+	}
+
+	return 0
+}
+
+// tryTableStart turns the last line of a paragraph into a table header when
+// the current line is a matching delimiter row
+func (p *blockParser) tryTableStart(container *Node, rest string) bool {
+	if !strings.Contains(rest, "|") || !tableDelimiterRegex.MatchString(rest) {
+		return false
+	}
+	content := strings.TrimSuffix(container.block.content.String(), "\n")
+	headerStart := strings.LastIndex(content, "\n") + 1
+	header := content[headerStart:]
+	if !strings.Contains(header, "|") {
+		return false
+	}
+	delimiters := splitTableRow(rest)
+	if len(splitTableRow(header)) != len(delimiters) {
+		return false
+	}
+
+	p.closeUnmatchedBlocks()
+	headerLine := p.lineNumber - 1
+	if headerStart > 0 {
+		container.block.content.Reset()
+		container.block.content.WriteString(content[:headerStart])
+		p.finalize(container)
+	} else {
+		p.tip = container.Parent
+		container.Unlink()
+	}
+	table := p.addChild(TableNode)
+	table.Line = headerLine
+	table.block.content.WriteString(header)
+	table.block.content.WriteByte('\n')
+	for _, delimiter := range delimiters {
+		table.block.align = append(table.block.align, columnAlignment(delimiter))
+	}
+	p.advanceOffset(len(p.line)-p.offset, false)
+	return true
+}
+
+// columnAlignment reads the alignment of a delimiter row cell such as :-:
+func columnAlignment(delimiter string) string {
+	left, right := strings.HasPrefix(delimiter, ":"), strings.HasSuffix(delimiter, ":")
+	switch {
+	case left && right:
+		return "center"
+	case left:
+		return "left"
+	case right:
+		return "right"
+	}
+	return ""
+}
+
+// startDefinitionList turns the lines of a paragraph into the terms of a
+// new definition list, or of the definition list right before it
+func (p *blockParser) startDefinitionList(paragraph *Node) {
+	content := strings.TrimSuffix(paragraph.block.content.String(), "\n")
+	list := paragraph.Prev
+	p.tip = paragraph.Parent
+	paragraph.Unlink()
+	if list != nil && list.Kind == DefinitionListNode {
+		list.block.open = true
+		p.tip = list
+	} else {
+		list = p.addChild(DefinitionListNode)
+		list.Line = paragraph.Line
+	}
+	for i, line := range strings.Split(content, "\n") {
+		term := p.addChild(DefinitionTermNode)
+		term.Line = paragraph.Line + i
+		term.block.content.WriteString(line)
+		p.finalize(term)
+	}
+}
+
+// parseDefinitionMarker consumes a ':' definition marker and the spaces
+// after it. Later lines of the definition are indented to its content.
+func (p *blockParser) parseDefinitionMarker() listData {
+	data := listData{markerOffset: p.indent}
+	p.advanceNextNonspace()
+	p.advanceOffset(1, true)
+	start := p.column
+	for isSpaceOrTab(p.peek(p.offset)) && p.column-start < 4 {
+		p.advanceOffset(1, true)
+	}
+	data.padding = 1 + p.column - start
+	return data
+}
+
+// parseListMarker checks for a list item marker at the current position
+func (p *blockParser) parseListMarker(container *Node) (listData, bool) {
+	if p.indent >= codeIndent {
+		return listData{}, false
+	}
+	rest := p.line[p.nextNonspace:]
+	data := listData{markerOffset: p.indent}
+	var marker string
+	if m := bulletMarkerRegex.FindString(rest); m != "" {
+		marker = m
+		data.bulletChar = m[0]
+	} else if m := orderedMarkerRegex.FindStringSubmatch(rest); m != nil && (container.Kind != ParagraphNode || m[1] == "1") {
+		marker = m[0]
+		data.ordered = true
+		data.start, _ = strconv.Atoi(m[1])
+		data.delimiter = m[2][0]
+	} else {
+		return listData{}, false
+	}
+
+	// The marker must be followed by whitespace or the end of the line
+	next := p.peek(p.nextNonspace + len(marker))
+	if next != 0 && !isSpaceOrTab(next) {
+		return listData{}, false
+	}
+	// An item interrupting a paragraph must not be empty
+	if container.Kind == ParagraphNode && strings.TrimSpace(rest[len(marker):]) == "" {
+		return listData{}, false
+	}
+
+	p.advanceNextNonspace()
+	p.advanceOffset(len(marker), true)
+	spacesStartColumn := p.column
+	spacesStartOffset := p.offset
+	for {
+		p.advanceOffset(1, true)
+		if p.column-spacesStartColumn >= 5 || !isSpaceOrTab(p.peek(p.offset)) {
+			break
+		}
+	}
+	blankItem := p.offset >= len(p.line)
+	spacesAfterMarker := p.column - spacesStartColumn
+	if spacesAfterMarker >= 5 || spacesAfterMarker < 1 || blankItem {
+		data.padding = len(marker) + 1
+		p.column = spacesStartColumn
+		p.offset = spacesStartOffset
+		if isSpaceOrTab(p.peek(p.offset)) {
+			p.advanceOffset(1, true)
+		}
+	} else {
+		data.padding = len(marker) + spacesAfterMarker
+	}
+	return data, true
+}
+
+func listsMatch(a, b listData) bool {
+	return a.ordered == b.ordered && a.delimiter == b.delimiter && a.bulletChar == b.bulletChar
+}
+
+// incorporateLine analyses one line of input and updates the tree
+func (p *blockParser) incorporateLine(line string) {
+	allMatched := true
+	container := p.doc
+	p.oldTip = p.tip
+	p.offset = 0
+	p.column = 0
+	p.blank = false
+	p.partiallyConsumedTab = false
+	p.lineNumber++
+	p.line = line
+
+	for container.LastChild != nil && container.LastChild.block != nil && container.LastChild.block.open {
+		container = container.LastChild
+		p.findNextNonspace()
+		switch p.continueBlock(container) {
+		case 1:
+			allMatched = false
+		case 2:
+			return
+		}
+		if !allMatched {
+			container = container.Parent
+			break
+		}
+	}
+
+	p.allClosed = container == p.oldTip
+	p.lastMatchedContainer = container
+
+	matchedLeaf := container.Kind != ParagraphNode && acceptsLines(container.Kind)
+	for !matchedLeaf {
+		p.findNextNonspace()
+		res := p.tryBlockStart(container)
+		if res == 0 {
+			p.advanceNextNonspace()
+			break
+		}
+		container = p.tip
+		if res == 2 {
+			matchedLeaf = true
+		}
+	}
+
+	if !p.allClosed && !p.blank && p.tip.Kind == ParagraphNode {
+		// Lazy paragraph continuation
+		p.addLine()
+	} else {
+		p.closeUnmatchedBlocks()
+		switch {
+		case container.Kind == HeadingNode || container.Kind == ThematicBreakNode:
+			// Single-line blocks already hold their content
+		case acceptsLines(container.Kind):
+			p.addLine()
+			if container.Kind == HTMLBlockNode {
+				t := container.block.htmlBlockType
+				if t >= 1 && t <= 5 && htmlBlockClose[t].MatchString(p.line[p.offset:]) {
+					p.finalize(container)
+				}
+			}
+		case p.offset < len(line) && !p.blank:
+			container = p.addChild(ParagraphNode)
+			p.advanceNextNonspace()
+			p.addLine()
+		}
+	}
+
+	// Block quote lines are never blank, and blank lines inside fenced code
+	// or right after an empty list item don't make a list loose
+	lastLineBlank := p.blank &&
+		container.Kind != BlockquoteNode &&
+		!(container.Kind == CodeBlockNode && container.Fenced) &&
+		!(container.Kind == ListItemNode && container.FirstChild == nil && container.Line == p.lineNumber)
+	for c := container; c != nil; c = c.Parent {
+		if c.block != nil {
+			c.block.lastLineBlank = lastLineBlank
+		}
+	}
+}
+
+// finalize closes a block and runs its kind-specific post-processing
+func (p *blockParser) finalize(block *Node) {
+	parent := block.Parent
+	block.block.open = false
+
+	switch block.Kind {
+	case ParagraphNode:
+		content := p.consumeReferences(block.block.content.String())
+		// A task list marker opens the first paragraph of a list item
+		if parent.Kind == ListItemNode && block.Prev == nil && p.hasFeature(featLists) {
+			if m := taskMarkerRegex.FindStringSubmatch(content); m != nil {
+				parent.Task, parent.Checked = true, m[1] != " "
+				content = content[len(m[0]):]
+			}
+		}
+		block.block.content.Reset()
+		block.block.content.WriteString(content)
+		if strings.TrimSpace(content) == "" {
+			block.Unlink()
+		}
+	case CodeBlockNode:
+		content := block.block.content.String()
+		if block.Fenced {
+			firstLine, rest, _ := strings.Cut(content, "\n")
+			block.Info = unescapeString(strings.TrimSpace(firstLine))
+			block.Literal = rest
+			if !block.block.fenceClosed {
+				p.warn(block.Line, block.block.fenceColumn, CodeUnclosedFence,
+					"code fence is never closed; the code block runs to the end of the %s", containerName(parent))
+			}
+			if lang := parseCodeInfo(block.Info).lang; lang != "" && !codeLangRegex.MatchString(lang) {
+				line, column := p.locate(block.Line, lang)
+				p.warn(line, column, CodeUnknownLanguage,
+					"code language %q is not a lexer name; the language is detected from the code instead", lang)
+			}
+		} else {
+			lines := strings.Split(content, "\n")
+			for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+				lines = lines[:len(lines)-1]
+			}
+			block.Literal = strings.Join(lines, "\n") + "\n"
+		}
+	case HTMLBlockNode:
+		block.Literal = strings.TrimSuffix(block.block.content.String(), "\n")
+		// Type 1 blocks keep their content verbatim and types 2 to 5 are
+		// comments and declarations
+		if t := block.block.htmlBlockType; t == 1 || t > 5 {
+			p.checkHTMLTags(block.Literal, block.Line, t == 1)
+		}
+	case ListNode:
+		block.Tight = isTightList(block)
+		if !block.Ordered && parent.Kind == ListItemNode && parent.Parent.Ordered {
+			p.warn(block.Line, p.sourceColumn(block.Line, ""), CodeListNesting,
+				"bullet list nested in a numbered item; MediaWiki may render it with double bullets")
+		}
+	case BlockquoteNode:
+		if p.hasFeature(featCallouts) {
+			p.detectCallout(block)
+		}
+	case TableNode:
+		p.buildTableRows(block)
+	}
+
+	p.tip = parent
+}
+
+// isTightList reports whether no item in the list is separated by a blank line
+func isTightList(list *Node) bool {
+	for item := list.FirstChild; item != nil; item = item.Next {
+		if endsWithBlankLine(item) && item.Next != nil {
+			return false
+		}
+		for sub := item.FirstChild; sub != nil; sub = sub.Next {
+			if endsWithBlankLine(sub) && (item.Next != nil || sub.Next != nil) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func endsWithBlankLine(block *Node) bool {
+	for block != nil && block.block != nil {
+		if block.block.lastLineBlank {
+			return true
+		}
+		if block.Kind != ListNode && block.Kind != ListItemNode {
+			return false
+		}
+		block = block.LastChild
+	}
+	return false
+}
+
+// detectCallout turns a block quote opening with an Obsidian [!type] marker
+// into a callout node. The rest of the marker line is the callout's title,
+// and a + or - after the marker makes it foldable.
+func (p *blockParser) detectCallout(block *Node) {
+	first := block.FirstChild
+	if first == nil || first.Kind != ParagraphNode {
+		return
+	}
+	content := first.block.content.String()
+	m := calloutMarkerRegex.FindStringSubmatch(content)
+	if m == nil {
+		return
+	}
+	calloutType := strings.ToLower(m[1])
+	if _, ok := calloutStyles[calloutType]; !ok {
+		line, column := p.locate(block.Line, "[!"+m[1]+"]")
+		p.warn(line, column, CodeUnknownCallout, "unknown callout type %q; it will be shown as a block quote", m[1])
+		return
+	}
+	block.Kind = CalloutNode
+	block.CalloutType = calloutType
+	block.Fold = m[2]
+	block.Title = strings.TrimSpace(m[3])
+
+	// The marker line is gone, so the paragraph starts on the next line
+	content = content[len(m[0]):]
+	first.block.content.Reset()
+	first.block.content.WriteString(content)
+	first.Line++
+	if strings.TrimSpace(content) == "" {
+		first.Unlink()
+	}
+}
+
+// buildTableRows splits the collected table lines into rows and cells. The
+// second line is the delimiter row, which was consumed when the table started.
+func (p *blockParser) buildTableRows(table *Node) {
+	lines := strings.Split(strings.TrimSuffix(table.block.content.String(), "\n"), "\n")
+	columns := len(splitTableRow(lines[0]))
+	for i, line := range lines {
+		if i == 1 {
+			continue
+		}
+		cells := splitTableRow(line)
+		row := &Node{Kind: TableRowNode, Header: i == 0, Line: table.Line + i}
+		if len(cells) > columns {
+			p.fail(row.Line, p.sourceColumn(row.Line, ""), CodeTableColumns,
+				"table row has %d cell(s) but the header has %d; the extra cells are dropped", len(cells), columns)
+		} else if len(cells) < columns {
+			p.warn(row.Line, p.sourceColumn(row.Line, ""), CodeTableColumns,
+				"table row has %d cell(s) but the header has %d; the missing cells are left empty", len(cells), columns)
+		}
+		for c := 0; c < columns; c++ {
+			cell := p.newBlock(TableCellNode, row.Line)
+			cell.block.open = false
+			cell.Align = table.block.align[c]
+			if c < len(cells) {
+				cell.block.content.WriteString(cells[c])
+			}
+			row.AppendChild(cell)
+		}
+		table.AppendChild(row)
+	}
+}
+
+// splitTableRow splits a table row into trimmed cell sources. Escaped pipes
+// and pipes inside code spans do not separate cells.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			run := 1
+			for i+run < len(line) && line[i+run] == '`' {
+				run++
+			}
+			end := findBacktickRun(line, i+run, run)
+			if end < 0 {
+				cell.WriteString(line[i : i+run])
+				i += run - 1
+				continue
+			}
+			cell.WriteString(strings.ReplaceAll(line[i:end+run], `\|`, "|"))
+			i = end + run - 1
+		case c == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	cells = append(cells, strings.TrimSpace(cell.String()))
+	return cells
+}
+
+// findBacktickRun returns the index of the next run of exactly n backticks
+// at or after start, or -1
+func findBacktickRun(s string, start, n int) int {
+	for i := start; i < len(s); i++ {
+		if s[i] != '`' {
+			continue
+		}
+		run := 1
+		for i+run < len(s) && s[i+run] == '`' {
+			run++
+		}
+		if run == n {
+			return i
+		}
+		i += run - 1
+	}
+	return -1
+}
+
+// consumeReferences strips leading link reference definitions from
+// paragraph content and records them
+func (p *blockParser) consumeReferences(content string) string {
+	for strings.HasPrefix(content, "[") {
+		n := parseReference(content, p.refs)
+		if n == 0 {
+			break
+		}
+		content = content[n:]
+	}
+	return content
+}
+
+// processInlines parses the inline content of every leaf block and drops
+// the parser bookkeeping from the tree
+func (p *blockParser) processInlines() {
+	Walk(p.doc, func(n *Node, entering bool) WalkStatus {
+		if !entering || n.block == nil {
+			return WalkContinue
+		}
+		switch n.Kind {
+		case ParagraphNode, HeadingNode, TableCellNode, DefinitionTermNode:
+			content := strings.TrimRight(n.block.content.String(), "\n")
+			if n.Kind != ParagraphNode {
+				content = strings.TrimSpace(content)
+			}
+			parseInlines(n, content, p.refs, p.footnotes, p.features)
+		}
+		n.block = nil
+		return WalkContinue
+	})
+}
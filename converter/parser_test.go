@@ -0,0 +1,153 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+// outline describes the block structure of a tree as indented kind names
+func outline(n *Node) string {
+	var b strings.Builder
+	depth := 0
+	Walk(n, func(node *Node, entering bool) WalkStatus {
+		if !node.Kind.IsBlock() {
+			return WalkSkipChildren
+		}
+		if !entering {
+			depth--
+			return WalkContinue
+		}
+		b.WriteString(strings.Repeat("  ", depth) + node.Kind.String() + "\n")
+		depth++
+		return WalkContinue
+	})
+	return b.String()
+}
+
+func TestParseBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Heading And Paragraph",
+			input:    "# Title\n\nSome text\nmore text",
+			expected: "Document\n  Heading\n  Paragraph\n",
+		},
+		{
+			name:     "Setext Heading",
+			input:    "Title\n---",
+			expected: "Document\n  Heading\n",
+		},
+		{
+			name:     "Front Matter",
+			input:    "---\ntitle: x\n---\n# Title",
+			expected: "Document\n  FrontMatter\n  Heading\n",
+		},
+		{
+			name:     "Nested List",
+			input:    "- a\n  - b\n- c",
+			expected: "Document\n  List\n    ListItem\n      Paragraph\n      List\n        ListItem\n          Paragraph\n    ListItem\n      Paragraph\n",
+		},
+		{
+			name:     "Fenced Code Keeps Blank Lines",
+			input:    "```go\na\n\nb\n```",
+			expected: "Document\n  CodeBlock\n",
+		},
+		{
+			name:     "Callout",
+			input:    "> [!warning]\n> Careful",
+			expected: "Document\n  Callout\n    Paragraph\n",
+		},
+		{
+			name:     "Unknown Callout Stays Blockquote",
+			input:    "> [!bogus]\n> text",
+			expected: "Document\n  Blockquote\n    Paragraph\n",
+		},
+		{
+			name:     "Table After Paragraph",
+			input:    "Intro\n| a | b |\n|---|---|\n| 1 | 2 |",
+			expected: "Document\n  Paragraph\n  Table\n    TableRow\n      TableCell\n      TableCell\n    TableRow\n      TableCell\n      TableCell\n",
+		},
+		{
+			name:     "Table Without Outer Pipes",
+			input:    "a | b\n--|:-:\n1 | 2",
+			expected: "Document\n  Table\n    TableRow\n      TableCell\n      TableCell\n    TableRow\n      TableCell\n      TableCell\n",
+		},
+		{
+			name:     "Blocks End A Table",
+			input:    "| a |\n|---|\n| 1 |\n## Heading | with pipe\n| a |\n|---|\n> quote | x\n\n| a |\n|---|\n- item | x",
+			expected: "Document\n  Table\n    TableRow\n      TableCell\n    TableRow\n      TableCell\n  Heading\n  Table\n    TableRow\n      TableCell\n  Blockquote\n    Paragraph\n  Table\n    TableRow\n      TableCell\n  List\n    ListItem\n      Paragraph\n",
+		},
+		{
+			name:     "Thematic Break",
+			input:    "a\n\n***\n\nb",
+			expected: "Document\n  Paragraph\n  ThematicBreak\n  Paragraph\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := outline(Parse(tt.input))
+			if got != tt.expected {
+				t.Errorf("Parse() =\n%v\nwant\n%v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseInlines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		kinds    []NodeKind
+		contents string
+	}{
+		{
+			name:     "Emphasis Kinds",
+			input:    "*a* **b** ==c== ~~d~~",
+			kinds:    []NodeKind{EmphasisNode, TextNode, StrongNode, TextNode, HighlightNode, TextNode, StrikethroughNode},
+			contents: "a b c d",
+		},
+		{
+			name:     "Code Span Wins Over Emphasis",
+			input:    "`*a*`",
+			kinds:    []NodeKind{CodeSpanNode},
+			contents: "*a*",
+		},
+		{
+			name:     "Link",
+			input:    "[text](https://example.com)",
+			kinds:    []NodeKind{LinkNode},
+			contents: "text",
+		},
+		{
+			name:     "Unmatched Delimiters Stay Text",
+			input:    "2 * 3 * 4",
+			kinds:    []NodeKind{TextNode},
+			contents: "2 * 3 * 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			para := Parse(tt.input).FirstChild
+			var kinds []NodeKind
+			for c := para.FirstChild; c != nil; c = c.Next {
+				kinds = append(kinds, c.Kind)
+			}
+			if len(kinds) != len(tt.kinds) {
+				t.Fatalf("Parse() inline kinds = %v, want %v", kinds, tt.kinds)
+			}
+			for i := range kinds {
+				if kinds[i] != tt.kinds[i] {
+					t.Fatalf("Parse() inline kinds = %v, want %v", kinds, tt.kinds)
+				}
+			}
+			if got := para.TextContent(); got != tt.contents {
+				t.Errorf("TextContent() = %q, want %q", got, tt.contents)
+			}
+		})
+	}
+}
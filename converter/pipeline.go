@@ -0,0 +1,266 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pass is one named step of the conversion pipeline. A pass implements
+// TreePass to transform the parsed document before rendering, TextPass to
+// rewrite the rendered wikitext, or both.
+type Pass interface {
+	Name() string
+}
+
+// TreePass transforms the document tree before it is rendered
+type TreePass interface {
+	Pass
+	ApplyTree(doc *Node, config *Config)
+}
+
+// TextPass rewrites the wikitext after rendering
+type TextPass interface {
+	Pass
+	ApplyText(text string, config *Config) string
+}
+
+// Names of the built-in passes
+const (
+	PassBoldTermDefinitions = "bold-term-definitions" // off by default
+	PassQuoteAttributions   = "quote-attributions"    // off by default
+	PassAddHighlights       = "add-highlights"
+	PassReverseChangelog    = "reverse-changelog"
+	PassPrettifyCheckmarks  = "prettify-checkmarks"
+	PassSectionRules        = "section-rules" // off by default
+)
+
+// treePassFunc adapts a function to the TreePass interface
+type treePassFunc struct {
+	name string
+	fn   func(doc *Node, config *Config)
+}
+
+func (p treePassFunc) Name() string                        { return p.name }
+func (p treePassFunc) ApplyTree(doc *Node, config *Config) { p.fn(doc, config) }
+
+// textPassFunc adapts a function to the TextPass interface
+type textPassFunc struct {
+	name string
+	fn   func(text string, config *Config) string
+}
+
+func (p textPassFunc) Name() string                                 { return p.name }
+func (p textPassFunc) ApplyText(text string, config *Config) string { return p.fn(text, config) }
+
+// NewTreePass creates a tree pass from a function
+func NewTreePass(name string, fn func(doc *Node, config *Config)) TreePass {
+	return treePassFunc{name: name, fn: fn}
+}
+
+// NewTextPass creates a text pass from a function
+func NewTextPass(name string, fn func(text string, config *Config) string) TextPass {
+	return textPassFunc{name: name, fn: fn}
+}
+
+// NewRegexpPass creates a text pass replacing every match of pattern with
+// replacement, which may refer to submatches as $1 or ${name}. It covers
+// site-specific rewrites such as turning ticket IDs into links.
+func NewRegexpPass(name, pattern, replacement string) (TextPass, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pass %q: %w", name, err)
+	}
+	return NewTextPass(name, func(text string, _ *Config) string {
+		return re.ReplaceAllString(text, replacement)
+	}), nil
+}
+
+// Pipeline is an ordered, configurable list of passes. Tree passes run on
+// the parsed document before it is rendered and text passes run on the
+// rendered wikitext, each group in pipeline order.
+type Pipeline struct {
+	passes   []Pass
+	disabled map[string]bool
+}
+
+// NewPipeline creates a pipeline running the given passes in order
+func NewPipeline(passes ...Pass) *Pipeline {
+	return &Pipeline{
+		passes:   append([]Pass(nil), passes...),
+		disabled: make(map[string]bool),
+	}
+}
+
+// DefaultPipeline returns the passes Convert runs when Config.Pipeline is
+// nil. The bold-term-definitions, quote-attributions and section-rules passes
+// are registered but disabled; Enable turns them on.
+func DefaultPipeline() *Pipeline {
+	p := NewPipeline(
+		NewTreePass(PassBoldTermDefinitions, func(doc *Node, _ *Config) { boldTermDefinitions(doc) }),
+		NewTreePass(PassQuoteAttributions, func(doc *Node, _ *Config) { quoteAttributions(doc) }),
+		NewTreePass(PassSectionRules, func(doc *Node, _ *Config) { sectionRules(doc) }),
+		NewTextPass(PassAddHighlights, func(text string, config *Config) string { return addHighlights(text, config.theme()) }),
+		NewTextPass(PassReverseChangelog, func(text string, _ *Config) string { return ReverseChangelogOrder(text) }),
+		NewTextPass(PassPrettifyCheckmarks, func(text string, _ *Config) string { return PrettifyCheckmarks(text) }),
+	)
+	p.disabled[PassBoldTermDefinitions] = true
+	p.disabled[PassQuoteAttributions] = true
+	p.disabled[PassSectionRules] = true
+	return p
+}
+
+// Passes returns the passes in pipeline order
+func (p *Pipeline) Passes() []Pass {
+	return append([]Pass(nil), p.passes...)
+}
+
+// Names returns the pass names in pipeline order
+func (p *Pipeline) Names() []string {
+	names := make([]string, len(p.passes))
+	for i, pass := range p.passes {
+		names[i] = pass.Name()
+	}
+	return names
+}
+
+func (p *Pipeline) index(name string) int {
+	for i, pass := range p.passes {
+		if pass.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Pipeline) mustIndex(name string) (int, error) {
+	i := p.index(name)
+	if i < 0 {
+		return -1, fmt.Errorf("unknown pass %q", name)
+	}
+	return i, nil
+}
+
+func (p *Pipeline) insertAt(i int, pass Pass) error {
+	if p.index(pass.Name()) >= 0 {
+		return fmt.Errorf("pass %q is already registered", pass.Name())
+	}
+	p.passes = append(p.passes, nil)
+	copy(p.passes[i+1:], p.passes[i:])
+	p.passes[i] = pass
+	return nil
+}
+
+// Append adds a pass at the end of the pipeline
+func (p *Pipeline) Append(pass Pass) error {
+	return p.insertAt(len(p.passes), pass)
+}
+
+// InsertBefore adds a pass directly before the named pass
+func (p *Pipeline) InsertBefore(name string, pass Pass) error {
+	i, err := p.mustIndex(name)
+	if err != nil {
+		return err
+	}
+	return p.insertAt(i, pass)
+}
+
+// InsertAfter adds a pass directly after the named pass
+func (p *Pipeline) InsertAfter(name string, pass Pass) error {
+	i, err := p.mustIndex(name)
+	if err != nil {
+		return err
+	}
+	return p.insertAt(i+1, pass)
+}
+
+// Replace swaps the named pass for another one in the same position
+func (p *Pipeline) Replace(name string, pass Pass) error {
+	i, err := p.mustIndex(name)
+	if err != nil {
+		return err
+	}
+	if j := p.index(pass.Name()); j >= 0 && j != i {
+		return fmt.Errorf("pass %q is already registered", pass.Name())
+	}
+	p.passes[i] = pass
+	return nil
+}
+
+// Remove deletes the named pass from the pipeline
+func (p *Pipeline) Remove(name string) error {
+	i, err := p.mustIndex(name)
+	if err != nil {
+		return err
+	}
+	p.passes = append(p.passes[:i], p.passes[i+1:]...)
+	delete(p.disabled, name)
+	return nil
+}
+
+// Move repositions the named pass to index, counted after its removal
+func (p *Pipeline) Move(name string, index int) error {
+	i, err := p.mustIndex(name)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(p.passes) {
+		return fmt.Errorf("pass index %d out of range", index)
+	}
+	pass := p.passes[i]
+	p.passes = append(p.passes[:i], p.passes[i+1:]...)
+	return p.insertAt(index, pass)
+}
+
+// Disable keeps the named pass registered but skips it when running
+func (p *Pipeline) Disable(name string) error {
+	if _, err := p.mustIndex(name); err != nil {
+		return err
+	}
+	p.disabled[name] = true
+	return nil
+}
+
+// Enable re-enables a disabled pass
+func (p *Pipeline) Enable(name string) error {
+	if _, err := p.mustIndex(name); err != nil {
+		return err
+	}
+	delete(p.disabled, name)
+	return nil
+}
+
+// Enabled reports whether the named pass is registered and enabled
+func (p *Pipeline) Enabled(name string) bool {
+	return p.index(name) >= 0 && !p.disabled[name]
+}
+
+// Run converts Markdown to wikitext: it parses the document, applies the
+// enabled tree passes, renders the tree and applies the enabled text passes
+func (p *Pipeline) Run(markdownText string, config Config) Result {
+	doc, diagnostics := ParseWithDiagnostics(markdownText)
+	for _, pass := range p.passes {
+		if tp, ok := pass.(TreePass); ok && !p.disabled[pass.Name()] {
+			tp.ApplyTree(doc, &config)
+		}
+	}
+
+	text := Render(doc, config)
+	if strings.HasSuffix(markdownText, "\n") && text != "" {
+		text += "\n"
+	}
+
+	for _, pass := range p.passes {
+		if tp, ok := pass.(TextPass); ok && !p.disabled[pass.Name()] {
+			text = tp.ApplyText(text, &config)
+		}
+	}
+
+	// Add CSS styling header if requested
+	if config.AddStyling {
+		if css := config.theme().StylingCSS(); css != "" {
+			text = css + "\n\n" + text
+		}
+	}
+	return Result{Text: text, Diagnostics: diagnostics}
+}
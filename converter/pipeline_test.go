@@ -0,0 +1,174 @@
+package converter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPipelineEditing(t *testing.T) {
+	upper := NewTextPass("upper", func(text string, _ *Config) string { return strings.ToUpper(text) })
+
+	tests := []struct {
+		name     string
+		edit     func(p *Pipeline) error
+		expected []string
+	}{
+		{
+			name:     "Default Order",
+			edit:     func(p *Pipeline) error { return nil },
+			expected: []string{PassBoldTermDefinitions, PassQuoteAttributions, PassSectionRules, PassAddHighlights, PassReverseChangelog, PassPrettifyCheckmarks},
+		},
+		{
+			name:     "Insert Before",
+			edit:     func(p *Pipeline) error { return p.InsertBefore(PassReverseChangelog, upper) },
+			expected: []string{PassBoldTermDefinitions, PassQuoteAttributions, PassSectionRules, PassAddHighlights, "upper", PassReverseChangelog, PassPrettifyCheckmarks},
+		},
+		{
+			name:     "Insert After",
+			edit:     func(p *Pipeline) error { return p.InsertAfter(PassPrettifyCheckmarks, upper) },
+			expected: []string{PassBoldTermDefinitions, PassQuoteAttributions, PassSectionRules, PassAddHighlights, PassReverseChangelog, PassPrettifyCheckmarks, "upper"},
+		},
+		{
+			name:     "Replace",
+			edit:     func(p *Pipeline) error { return p.Replace(PassAddHighlights, upper) },
+			expected: []string{PassBoldTermDefinitions, PassQuoteAttributions, PassSectionRules, "upper", PassReverseChangelog, PassPrettifyCheckmarks},
+		},
+		{
+			name:     "Remove",
+			edit:     func(p *Pipeline) error { return p.Remove(PassReverseChangelog) },
+			expected: []string{PassBoldTermDefinitions, PassQuoteAttributions, PassSectionRules, PassAddHighlights, PassPrettifyCheckmarks},
+		},
+		{
+			name:     "Move",
+			edit:     func(p *Pipeline) error { return p.Move(PassPrettifyCheckmarks, 0) },
+			expected: []string{PassPrettifyCheckmarks, PassBoldTermDefinitions, PassQuoteAttributions, PassSectionRules, PassAddHighlights, PassReverseChangelog},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPipeline()
+			if err := tt.edit(p); err != nil {
+				t.Fatalf("edit failed: %v", err)
+			}
+			if got := p.Names(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Names() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	p := DefaultPipeline()
+	if err := p.Disable("missing"); err == nil {
+		t.Error("Disable() of unknown pass should fail")
+	}
+	if err := p.Append(NewTextPass(PassAddHighlights, nil)); err == nil {
+		t.Error("Append() of duplicate name should fail")
+	}
+	if _, err := NewRegexpPass("bad", "(", ""); err == nil {
+		t.Error("NewRegexpPass() with invalid pattern should fail")
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	ticketLinks, err := NewRegexpPass("ticket-links", `\b(PROJ-\d+)\b`, "[https://jira.example.com/browse/$1 $1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	productTemplate := NewTreePass("product-template", func(doc *Node, _ *Config) {
+		Walk(doc, func(n *Node, entering bool) WalkStatus {
+			if before, after, ok := strings.Cut(n.Literal, "Public 360"); entering && n.Kind == TextNode && ok {
+				template := NewNode(WikitextNode)
+				template.Literal = "{{Product|P360}}"
+				n.Literal = before
+				n.InsertAfter(template)
+				template.InsertAfter(newText(after))
+			}
+			return WalkContinue
+		})
+	})
+
+	tests := []struct {
+		name     string
+		setup    func(p *Pipeline)
+		config   Config
+		input    string
+		expected string
+	}{
+		{
+			name:     "Checkmarks By Default",
+			setup:    func(p *Pipeline) {},
+			input:    "Done ✓",
+			expected: "Done ✅",
+		},
+		{
+			name:     "Disabled Pass Is Skipped",
+			setup:    func(p *Pipeline) { _ = p.Disable(PassPrettifyCheckmarks) },
+			input:    "Done ✓",
+			expected: "Done ✓",
+		},
+		{
+			name:     "Section Rules Off By Default",
+			setup:    func(p *Pipeline) {},
+			input:    "Intro\n\n## Setup\n\nText",
+			expected: "Intro\n\n==<span style=\"color:#021e57;\">Setup</span>==\n\nText",
+		},
+		{
+			name:     "Enabled Section Rules",
+			setup:    func(p *Pipeline) { _ = p.Enable(PassSectionRules) },
+			input:    "Intro\n\n## Setup\n\nText\n\n---\n\n## Usage\n\n### Details",
+			expected: "Intro\n\n----\n\n==<span style=\"color:#021e57;\">Setup</span>==\n\nText\n\n----\n\n==<span style=\"color:#021e57;\">Usage</span>==\n\n===<span style=\"color:#021e57;\">Details</span>===",
+		},
+		{
+			name:     "Bold Term Definitions Off By Default",
+			setup:    func(p *Pipeline) {},
+			input:    "**Term**: description",
+			expected: "'''Term''': description",
+		},
+		{
+			name:     "Enabled Bold Term Definitions",
+			setup:    func(p *Pipeline) { _ = p.Enable(PassBoldTermDefinitions) },
+			input:    "**Term**: description",
+			expected: "; Term\n: description",
+		},
+		{
+			name:     "Quote Attributions Off By Default",
+			setup:    func(p *Pipeline) {},
+			input:    "> Quote\n> — Author",
+			expected: "<blockquote>Quote — Author</blockquote>",
+		},
+		{
+			name:     "Enabled Quote Attributions",
+			setup:    func(p *Pipeline) { _ = p.Enable(PassQuoteAttributions) },
+			input:    "> Quote\n> — Author",
+			expected: "<blockquote>\nQuote\n\n<div style=\"text-align:right;\">— <cite>Author</cite></div>\n</blockquote>",
+		},
+		{
+			name:     "Custom Text Pass",
+			setup:    func(p *Pipeline) { _ = p.Append(ticketLinks) },
+			input:    "Fixed in PROJ-42.",
+			expected: "Fixed in [https://jira.example.com/browse/PROJ-42 PROJ-42].",
+		},
+		{
+			name:     "Custom Tree Pass Skips Code",
+			setup:    func(p *Pipeline) { _ = p.Append(productTemplate) },
+			input:    "Public 360 and `Public 360`",
+			expected: "{{Product|P360}} and <code style=\"" + inlineCodeStyle + "\">Public 360</code>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPipeline()
+			tt.setup(p)
+			config := tt.config
+			config.Pipeline = p
+			got := Convert(tt.input, config).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	attributionRegex     = regexp.MustCompile(`^(?:—|―|--)(?:[ \t]+|$)`)
+	wikiQuoteLineRegex   = regexp.MustCompile(`^<blockquote>(.*)</blockquote>$`)
+	wikiAttributionRegex = regexp.MustCompile(`^<div style="text-align:right;">— <cite>(.*)</cite></div>$`)
+)
+
+// renderBlockquote renders a block quote as a <blockquote> element. A quote
+// of one paragraph stays on one line, so it also fits in a list item.
+func (r *renderer) renderBlockquote(n *Node) string {
+	switch {
+	case n.FirstChild == nil:
+		return "<blockquote></blockquote>"
+	case n.FirstChild == n.LastChild && n.FirstChild.Kind == ParagraphNode:
+		return "<blockquote>" + r.renderInlines(n.FirstChild, " ") + "</blockquote>"
+	}
+	return "<blockquote>\n" + r.renderBlocks(n) + "\n</blockquote>"
+}
+
+// renderAttribution renders the attribution of a block quote as a
+// right-aligned cite footer
+func (r *renderer) renderAttribution(n *Node) string {
+	return `<div style="text-align:right;">— <cite>` + r.renderInlines(n, " ") + "</cite></div>"
+}
+
+// quoteAttributions moves a last line reading "— Author" out of each block
+// quote's final paragraph into an attribution node
+func quoteAttributions(doc *Node) {
+	var quotes []*Node
+	Walk(doc, func(n *Node, entering bool) WalkStatus {
+		if entering && n.Kind == BlockquoteNode {
+			quotes = append(quotes, n)
+		}
+		return WalkContinue
+	})
+
+	for _, quote := range quotes {
+		paragraph := quote.LastChild
+		if paragraph == nil || paragraph.Kind != ParagraphNode {
+			continue
+		}
+		lines := inlineLines(paragraph)
+		last := lines[len(lines)-1]
+		// A quote holding nothing but the attribution line stays a quote
+		if !isAttribution(last) || (len(lines) == 1 && paragraph.Prev == nil) {
+			continue
+		}
+
+		attribution := NewNode(AttributionNode)
+		attribution.Line = paragraph.Line + len(lines) - 1
+		if len(lines) > 1 {
+			last[0].Prev.Unlink()
+		}
+		for _, child := range last {
+			attribution.AppendChild(child)
+		}
+		first := attribution.FirstChild
+		first.Literal = strings.TrimPrefix(first.Literal, attributionRegex.FindString(first.Literal))
+		if first.Literal == "" {
+			first.Unlink()
+		}
+		if paragraph.FirstChild == nil {
+			paragraph.Unlink()
+		}
+		quote.AppendChild(attribution)
+	}
+}
+
+// isAttribution reports whether a line of inlines is a dash followed by the
+// name of the quote's author
+func isAttribution(line []*Node) bool {
+	if len(line) == 0 || line[0].Kind != TextNode {
+		return false
+	}
+	dash := attributionRegex.FindString(line[0].Literal)
+	if dash == "" {
+		return false
+	}
+	return strings.TrimSpace(line[0].Literal[len(dash):]) != "" || len(line) > 1
+}
+
+// quoteEnd returns the index of the </blockquote> line closing the quote
+// that starts at lines[start], skipping the quotes nested in it
+func quoteEnd(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		switch strings.TrimSpace(lines[i]) {
+		case "<blockquote>":
+			depth++
+		case "</blockquote>":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(lines) - 1
+}
+
+// quote converts a <blockquote> element to a Markdown block quote
+func (r *reverser) quote(lines []string) string {
+	var content string
+	if m := wikiQuoteLineRegex.FindStringSubmatch(strings.TrimSpace(lines[0])); m != nil {
+		content = r.inline(m[1])
+	} else {
+		body := lines[1:]
+		if len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "</blockquote>" {
+			body = body[:len(body)-1]
+		}
+		content = r.blocks(strings.Join(body, "\n"))
+	}
+
+	var out []string
+	for _, line := range strings.Split(content, "\n") {
+		out = append(out, strings.TrimRight("> "+line, " "))
+	}
+	return strings.Join(out, "\n")
+}
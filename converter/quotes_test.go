@@ -0,0 +1,97 @@
+package converter
+
+import "testing"
+
+func TestConvertQuoteAttribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Last Line",
+			input:    "> Simplicity is prerequisite for reliability.\n> — Edsger W. Dijkstra",
+			expected: "<blockquote>\nSimplicity is prerequisite for reliability.\n\n<div style=\"text-align:right;\">— <cite>Edsger W. Dijkstra</cite></div>\n</blockquote>",
+		},
+		{
+			name:     "Own Paragraph With Markup",
+			input:    "> Quoted.\n>\n> -- [Ada](https://example.com), *Notes*",
+			expected: "<blockquote>\nQuoted.\n\n<div style=\"text-align:right;\">— <cite>[https://example.com Ada], ''Notes''</cite></div>\n</blockquote>",
+		},
+		{
+			name:     "Nested Quote",
+			input:    "> Outer\n>\n> > Inner\n> > ― Someone",
+			expected: "<blockquote>\nOuter\n\n<blockquote>\nInner\n\n<div style=\"text-align:right;\">— <cite>Someone</cite></div>\n</blockquote>\n</blockquote>",
+		},
+		{
+			name:     "Only Attribution",
+			input:    "> — Anonymous",
+			expected: "<blockquote>— Anonymous</blockquote>",
+		},
+		{
+			name:     "Dash Without Name",
+			input:    "> Wait for it\n> —",
+			expected: "<blockquote>Wait for it —</blockquote>",
+		},
+		{
+			name:     "Not A Dash Line",
+			input:    "> One\n> ---- Two",
+			expected: "<blockquote>One ---- Two</blockquote>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := DefaultPipeline()
+			_ = pipeline.Enable(PassQuoteAttributions)
+			got := Convert(tt.input, Config{Pipeline: pipeline}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuotesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		attribution bool
+	}{
+		{
+			name:  "Single Paragraph",
+			input: "> A ''quoted'' line",
+		},
+		{
+			name:  "Blocks",
+			input: "> First\n>\n> ```go\n> x := 1\n> ```\n>\n> > Nested\n> >\n> > Twice",
+		},
+		{
+			name:  "In List Item",
+			input: "- Step\n\n  > Note the output",
+		},
+		{
+			name:        "Attribution",
+			input:       "> Quoted.\n>\n> — Ada Lovelace",
+			attribution: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := DefaultPipeline()
+			if tt.attribution {
+				_ = pipeline.Enable(PassQuoteAttributions)
+			}
+			config := Config{Pipeline: pipeline}
+			wikitext := Convert(tt.input, config).Text
+			markdown := ToMarkdown(wikitext, config)
+			if markdown != tt.input {
+				t.Errorf("ToMarkdown() = %q, want %q", markdown, tt.input)
+			}
+			if again := Convert(markdown, config).Text; again != wikitext {
+				t.Errorf("round trip through %q:\ngot  %q\nwant %q", markdown, again, wikitext)
+			}
+		})
+	}
+}
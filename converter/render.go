@@ -0,0 +1,436 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var externalURLRegex = regexp.MustCompile(`(?i)^(?:https?|ftp|ftps|mailto|irc|ircs|news|sftp|ssh):|^//`)
+
+// renderer writes a document tree as MediaWiki wikitext
+type renderer struct {
+	config       Config
+	theme        *Theme
+	headingSlugs map[string]string // GitHub-style anchors to heading text
+	inTable      bool              // rendering a table cell, where a bare '|' starts a new cell
+	inTerm       bool              // rendering a definition term, where a bare ':' starts the definition
+	inBox        bool              // rendering the blocks of a callout box, whose lines are in a table cell
+
+	footnotes     map[string]*Node // footnote definitions by label key
+	usedFootnotes map[string]bool  // label keys already written as a named ref
+	hasRefs       bool             // the page contains <ref> tags
+}
+
+// Render converts a document tree produced by Parse into MediaWiki wikitext
+func Render(doc *Node, config Config) string {
+	r := &renderer{
+		config:        config,
+		theme:         config.theme(),
+		headingSlugs:  collectHeadingSlugs(doc),
+		footnotes:     collectFootnotes(doc),
+		usedFootnotes: make(map[string]bool),
+	}
+	body := r.renderBlocks(doc)
+
+	// Page metadata goes before the body, footnotes and categories after it
+	var header, footer string
+	if doc.FirstChild != nil && doc.FirstChild.Kind == FrontMatterNode {
+		header, footer = r.renderFrontMatter(doc.FirstChild)
+	}
+	var parts []string
+	for _, part := range []string{header, body, r.renderReferences(body), footer} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderBlocks renders the block children of n separated by blank lines
+func (r *renderer) renderBlocks(n *Node) string {
+	var parts []string
+	for child := n.FirstChild; child != nil; child = child.Next {
+		if child.Kind == FrontMatterNode || child.Kind == FootnoteDefinitionNode || isTableHint(child) {
+			continue
+		}
+		parts = append(parts, r.renderBlock(child))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (r *renderer) renderBlock(n *Node) string {
+	switch n.Kind {
+	case ParagraphNode:
+		text := escapeLineStarts(r.renderInlines(n, "\n"))
+		if r.inBox {
+			text = escapeTableLineStarts(text)
+		}
+		return text
+	case HeadingNode:
+		return r.renderHeading(n)
+	case ThematicBreakNode:
+		return "----"
+	case CodeBlockNode:
+		return r.renderCodeBlock(n)
+	case HTMLBlockNode:
+		return n.Literal
+	case BlockquoteNode:
+		return r.renderBlockquote(n)
+	case CalloutNode:
+		return r.renderCallout(n)
+	case ListNode:
+		return r.taskSummary(n) + r.renderList(n, "")
+	case TableNode:
+		return r.renderTable(n)
+	case DefinitionListNode:
+		return r.renderDefinitionList(n)
+	case AttributionNode:
+		return r.renderAttribution(n)
+	}
+	return r.renderBlocks(n)
+}
+
+func (r *renderer) renderHeading(n *Node) string {
+	return r.theme.heading(n.Level, r.renderInlines(n, " "))
+}
+
+// detectLanguage guesses a syntax highlighting language for unlabelled code
+func detectLanguage(code string) string {
+	code = strings.TrimSpace(code)
+	upper := strings.ToUpper(code)
+	switch {
+	case strings.HasPrefix(code, "{") || strings.HasPrefix(code, "["):
+		return "json"
+	case strings.HasPrefix(code, "<"):
+		return "xml"
+	case strings.Contains(upper, "SELECT") || strings.Contains(upper, "FROM"):
+		return "sql"
+	}
+	return "text"
+}
+
+// renderCallout renders an Obsidian callout as a styled MediaWiki box.
+// Paragraphs are joined with <br/> after the label; other blocks are
+// rendered in full on lines of their own.
+func (r *renderer) renderCallout(n *Node) string {
+	if r.config.Callouts.Template != "" {
+		return r.renderCalloutTemplate(n)
+	}
+	style := r.theme.callout(n.CalloutType)
+
+	var content, separator string
+	if hasOnlyParagraphs(n) {
+		var parts []string
+		for child := n.FirstChild; child != nil; child = child.Next {
+			parts = append(parts, r.renderInlines(child, "<br/>"))
+		}
+		content = strings.Join(parts, "<br/>")
+
+		// Content that starts on the marker line follows the label directly
+		switch {
+		case content == "":
+		case n.FirstChild.Line == n.Line:
+			separator = " "
+		default:
+			separator = "<br/>"
+		}
+	} else {
+		inBox := r.inBox
+		r.inBox = true
+		content = r.renderBlocks(n)
+		r.inBox = inBox
+		separator = "\n"
+	}
+
+	box := "width:100%;"
+	if style.Background != "" {
+		box = "background-color:" + style.Background + "; " + box
+	}
+	if style.Border != "" {
+		box = "border-left:4px solid " + style.Border + "; " + box
+	}
+	strong := "<strong>"
+	if style.Text != "" {
+		strong = `<strong style="color:` + style.Text + `;">`
+	}
+	title := style.title()
+	if n.Title != "" {
+		title = CalloutStyle{Emoji: style.Emoji, Label: escapeText(n.Title)}.title()
+	}
+
+	if n.Fold == "" {
+		return fmt.Sprintf(`{| class="wikitable" style="%s"
+| <div style="padding:0.5em;">
+%s%s:</strong>%s%s
+</div>
+|}`, box, strong, title, separator, content)
+	}
+
+	// A collapsed table keeps only its first row, so the title gets a row
+	// of its own
+	class := "wikitable mw-collapsible"
+	if n.Fold == "-" {
+		class += " mw-collapsed"
+	}
+	return fmt.Sprintf(`{| class="%s" style="%s"
+| <div style="padding:0.5em;">
+%s%s:</strong>
+</div>
+|-
+| <div style="padding:0.5em;">
+%s
+</div>
+|}`, class, box, strong, title, content)
+}
+
+// hasOnlyParagraphs reports whether every child of n is a paragraph
+func hasOnlyParagraphs(n *Node) bool {
+	for child := n.FirstChild; child != nil; child = child.Next {
+		if child.Kind != ParagraphNode {
+			return false
+		}
+	}
+	return true
+}
+
+// renderList renders a list using MediaWiki's prefix nesting, where prefix
+// holds the markers of the enclosing lists
+func (r *renderer) renderList(n *Node, prefix string) string {
+	if prefix == "" && holdsBox(n) {
+		return r.renderHTMLList(n)
+	}
+	marker := "*"
+	if n.Ordered {
+		marker = "#"
+	}
+	prefix += marker
+
+	var lines []string
+	for item := n.FirstChild; item != nil; item = item.Next {
+		lines = append(lines, r.renderListItem(item, prefix)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderListItem renders the blocks of one list item. Only the first block
+// starts the item; later blocks continue it with a ':' prefix.
+func (r *renderer) renderListItem(item *Node, prefix string) []string {
+	box := ""
+	if item.Task {
+		box = r.config.Tasks.checkbox(item.Checked) + " "
+	}
+
+	var lines []string
+	for child := item.FirstChild; child != nil; child = child.Next {
+		lead := prefix + " " + box
+		if child != item.FirstChild {
+			lead = prefix + ": "
+		}
+		switch child.Kind {
+		case ParagraphNode:
+			lines = append(lines, lead+r.renderInlines(child, " "))
+		case ListNode:
+			lines = append(lines, r.renderList(child, prefix))
+		case TableNode, CalloutNode:
+			// Only lists nested in a definition get here; the table ends
+			// the list and the next item opens it again
+			lines = append(lines, r.renderBlock(child))
+		default:
+			lines = append(lines, lead+r.renderBlock(child))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, strings.TrimRight(prefix+" "+box, " "))
+	}
+	return lines
+}
+
+// holdsBox reports whether an item of the list, or of a list nested in it,
+// holds a table or callout
+func holdsBox(list *Node) bool {
+	for item := list.FirstChild; item != nil; item = item.Next {
+		for child := item.FirstChild; child != nil; child = child.Next {
+			if child.Kind == TableNode || child.Kind == CalloutNode || (child.Kind == ListNode && holdsBox(child)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderHTMLList renders a list as <ol> or <ul> elements. MediaWiki opens a
+// table only at the start of a line, which ends a list written with
+// prefixes and restarts its numbering, so lists whose items hold tables or
+// callouts are written in HTML instead.
+func (r *renderer) renderHTMLList(n *Node) string {
+	tag := "ul"
+	if n.Ordered {
+		tag = "ol"
+	}
+	lines := []string{"<" + tag + ">"}
+	for item := n.FirstChild; item != nil; item = item.Next {
+		lines = append(lines, r.renderHTMLListItem(item))
+	}
+	lines = append(lines, "</"+tag+">")
+	return strings.Join(lines, "\n")
+}
+
+// renderHTMLListItem renders one <li> element. The first paragraph shares
+// the line of the tag and every other block starts a line of its own.
+func (r *renderer) renderHTMLListItem(item *Node) string {
+	first := "<li>"
+	if item.Task {
+		first += r.config.Tasks.checkbox(item.Checked) + " "
+	}
+	child := item.FirstChild
+	if child != nil && child.Kind == ParagraphNode {
+		first += r.renderInlines(child, " ")
+		child = child.Next
+	}
+
+	lines := []string{strings.TrimRight(first, " ")}
+	for ; child != nil; child = child.Next {
+		switch {
+		case child.Kind == ListNode:
+			lines = append(lines, r.renderHTMLList(child))
+		case child.Kind == ParagraphNode && child.Prev.Kind == ParagraphNode:
+			lines = append(lines, "", r.renderBlock(child))
+		default:
+			lines = append(lines, r.renderBlock(child))
+		}
+	}
+	if len(lines) == 1 {
+		return lines[0] + "</li>"
+	}
+	return strings.Join(append(lines, "</li>"), "\n")
+}
+
+func (r *renderer) renderTable(n *Node) string {
+	style := r.tableStyleFor(n)
+	lines := style.opening()
+	for row := n.FirstChild; row != nil; row = row.Next {
+		lines = append(lines, "|-")
+		for cell := row.FirstChild; cell != nil; cell = cell.Next {
+			marker := "|"
+			if row.Header || (style.HeaderColumn && cell == row.FirstChild) {
+				marker = "!"
+			}
+			r.inTable = true
+			content := escapeCell(r.renderInlines(cell, " "), marker == "!")
+			r.inTable = false
+			if cell.Align != "" {
+				content = `style="text-align:` + cell.Align + `;" | ` + content
+			}
+			lines = append(lines, strings.TrimRight(marker+" "+content, " "))
+		}
+	}
+	lines = append(lines, "|}")
+	return strings.Join(lines, "\n")
+}
+
+// cellSafe encodes literal pipes inside table cells so MediaWiki does not
+// read them as cell separators
+func (r *renderer) cellSafe(s string) string {
+	if !r.inTable {
+		return s
+	}
+	return strings.ReplaceAll(s, "|", "&#124;")
+}
+
+// renderInlines renders the inline children of n. softBreak is written for
+// source line breaks, since some contexts must stay on a single line.
+// Adjacent text nodes are escaped together so markup split across them is
+// caught.
+func (r *renderer) renderInlines(n *Node, softBreak string) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.Next {
+		if child.Kind != TextNode {
+			r.renderInline(&b, child, softBreak)
+			continue
+		}
+		first, text := child, child.Literal
+		for child.Next != nil && child.Next.Kind == TextNode {
+			child = child.Next
+			text += child.Literal
+		}
+		b.WriteString(r.renderText(text, first.Prev, child.Next, n))
+	}
+	return b.String()
+}
+
+// renderText escapes literal text between two sibling nodes
+func (r *renderer) renderText(text string, prev, next, parent *Node) string {
+	return r.termSafe(r.cellSafe(escapeQuotes(escapeText(text), prev, next, parent)))
+}
+
+func (r *renderer) renderInline(b *strings.Builder, n *Node, softBreak string) {
+	switch n.Kind {
+	case TextNode:
+		b.WriteString(r.renderText(n.Literal, n.Prev, n.Next, n.Parent))
+	case HTMLInlineNode, WikitextNode:
+		b.WriteString(n.Literal)
+	case SoftBreakNode:
+		b.WriteString(softBreak)
+	case HardBreakNode:
+		b.WriteString("<br/>")
+		if softBreak == "\n" {
+			b.WriteString("\n")
+		}
+	case CodeSpanNode:
+		b.WriteString(r.theme.codeTag() + r.termSafe(r.cellSafe(escapeCode(n.Literal))) + "</code>")
+	case EmphasisNode:
+		b.WriteString("''" + r.renderInlines(n, softBreak) + "''")
+	case StrongNode:
+		b.WriteString("'''" + r.renderInlines(n, softBreak) + "'''")
+	case HighlightNode:
+		b.WriteString(r.theme.markTag() + r.renderInlines(n, softBreak) + "</mark>")
+	case StrikethroughNode:
+		b.WriteString("<s>" + r.renderInlines(n, softBreak) + "</s>")
+	case LinkNode:
+		b.WriteString(r.renderLink(n))
+	case ImageNode:
+		b.WriteString(r.renderImage(n))
+	case WikiLinkNode:
+		b.WriteString(r.renderWikiLink(n))
+	case EmbedNode:
+		b.WriteString(r.renderEmbed(n))
+	case FootnoteRefNode:
+		b.WriteString(r.renderFootnoteRef(n))
+	case InlineFootnoteNode:
+		b.WriteString(r.renderInlineFootnote(n))
+	default:
+		b.WriteString(r.renderInlines(n, softBreak))
+	}
+}
+
+// renderLink renders external links in MediaWiki bracket syntax and links
+// to other notes or headings as internal links. Links to other local files
+// have no wiki equivalent, so only their text is kept.
+func (r *renderer) renderLink(n *Node) string {
+	text := r.renderInlines(n, " ")
+	if !externalURLRegex.MatchString(n.Destination) {
+		if link, ok := r.renderLocalLink(n); ok {
+			return link
+		}
+		return text
+	}
+	url := strings.ReplaceAll(n.Destination, " ", "%20")
+	if text == n.Destination || text == "" {
+		return url
+	}
+	return "[" + url + " " + text + "]"
+}
+
+// renderImage renders remote images as links and local ones as File: links
+func (r *renderer) renderImage(n *Node) string {
+	alt := n.TextContent()
+	if !externalURLRegex.MatchString(n.Destination) {
+		return r.renderLocalImage(n, alt)
+	}
+	url := strings.ReplaceAll(n.Destination, " ", "%20")
+	if alt == "" {
+		return url
+	}
+	return "[" + url + " " + alt + "]"
+}
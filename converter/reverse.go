@@ -0,0 +1,841 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Patterns for the wikitext this converter emits
+var (
+	wikiHeadingRegex      = regexp.MustCompile(`^(={1,6})\s*(.*?)\s*(={1,6})$`)
+	wikiHeadingSpanRegex  = regexp.MustCompile(`^<span style="[^"]*">(.*)</span>$`)
+	wikiCodeBlockRegex    = regexp.MustCompile(`^<syntaxhighlight\b([^>]*)>(.*)$`)
+	wikiCalloutRegex      = regexp.MustCompile(`(?s)^\{\| class="wikitable( mw-collapsible(?: mw-collapsed)?)?" style="[^"]*"\n\| <div style="padding:0\.5em;">\n<strong[^>]*>(.*?):</strong>(?:\n</div>\n\|-\n\| <div style="padding:0\.5em;">\n| |<br/>)?(.*)\n</div>\n\|\}$`)
+	wikiListRegex         = regexp.MustCompile(`^([*#]+)(:?)\s?(.*)$`)
+	wikiDisplayTitleRegex = regexp.MustCompile(`^\{\{DISPLAYTITLE:(.*)\}\}$`)
+	wikiTemplateCallRegex = regexp.MustCompile(`^\{\{([^:{}|]+)\|(.*)\}\}$`)
+	wikiCategoryRegex     = regexp.MustCompile(`^\[\[Category:([^\]|]+)(?:\|[^\]]*)?\]\]$`)
+	wikiReferencesRegex   = regexp.MustCompile(`(?i)^<references\s*/?>$`)
+	wikiCellAttrsRegex    = regexp.MustCompile(`^\s*((?:[\w-]+="[^"]*"\s*)+)\|([^|].*|)$`)
+	wikiTextAlignRegex    = regexp.MustCompile(`text-align:\s*(left|center|right)`)
+
+	wikiMarkedCodeRegex = regexp.MustCompile(`<mark[^>]*>(<code[^>]*>.*?</code>)</mark>`)
+	wikiCodeRegex       = regexp.MustCompile(`<code[^>]*>(.*?)</code>`)
+	wikiNowikiRegex     = regexp.MustCompile(`<nowiki>(.*?)</nowiki>`)
+	wikiRefRegex        = regexp.MustCompile(`<ref(?:\s+name="([^"]*)")?\s*(?:/>|>(.*?)</ref>)`)
+	wikiFileLinkRegex   = regexp.MustCompile(`\[\[(?i:File|Image):([^\]|]+)((?:\|[^\]]*)?)\]\]`)
+	wikiMediaLinkRegex  = regexp.MustCompile(`\[\[(?i:Media):([^\]|]+)(?:\|[^\]]*)?\]\]`)
+	wikiInternalRegex   = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+	wikiTransclRegex    = regexp.MustCompile(`\{\{:([^{}|]+)\}\}`)
+	wikiExternalRegex   = regexp.MustCompile(`\[((?:https?|ftps?|mailto|irc|ircs|news|sftp|ssh):[^\s\]]+|//[^\s\]]+)(?:\s+([^\]]*))?\]`)
+	wikiBoldItalicRegex = regexp.MustCompile(`'''''(.+?)'''''`)
+	wikiBoldRegex       = regexp.MustCompile(`'''(.+?)'''`)
+	wikiItalicRegex     = regexp.MustCompile(`''(.+?)''`)
+	wikiMarkRegex       = regexp.MustCompile(`<mark[^>]*>(.*?)</mark>`)
+	wikiStrikeRegex     = regexp.MustCompile(`<(?:s|del)>(.*?)</(?:s|del)>`)
+	wikiPlaceholder     = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// reverser converts wikitext back to Markdown
+type reverser struct {
+	config Config
+
+	title       string
+	params      [][2]string // infobox parameters in order
+	categories  []string
+	footnotes   []string // footnote definitions in order of first use
+	refNames    map[string]bool
+	placeholder []string // converted code spans kept out of later rewrites
+}
+
+// ToMarkdown converts wikitext produced by Convert back into Obsidian
+// Markdown. It understands the constructs this package emits: styled
+// headings and inline code, syntaxhighlight blocks, callout boxes, block
+// quotes, tables, lists, links, files, refs and page metadata. The
+// changelog is put back in source order when the reverse-changelog pass is
+// enabled, while prettified checkmarks are kept as they are.
+func ToMarkdown(wikitext string, config Config) string {
+	pipeline := config.Pipeline
+	if pipeline == nil {
+		pipeline = DefaultPipeline()
+	}
+	if pipeline.Enabled(PassReverseChangelog) {
+		wikitext = ReverseChangelogOrder(wikitext)
+	}
+
+	r := &reverser{config: config, refNames: make(map[string]bool)}
+	body := r.blocks(stripStylingCSS(strings.ReplaceAll(wikitext, "\r\n", "\n"), config.theme()))
+
+	var parts []string
+	if fm := r.frontMatter(); fm != "" {
+		parts = append(parts, fm)
+	}
+	if body != "" {
+		parts = append(parts, body)
+	}
+	if len(r.footnotes) > 0 {
+		parts = append(parts, strings.Join(r.footnotes, "\n"))
+	}
+	text := strings.Join(parts, "\n\n")
+	if strings.HasSuffix(wikitext, "\n") && text != "" {
+		text += "\n"
+	}
+	return text
+}
+
+// stripStylingCSS removes the CSS header added by Config.AddStyling
+func stripStylingCSS(text string, theme *Theme) string {
+	css := theme.StylingCSS()
+	if css != "" && strings.HasPrefix(text, css) {
+		return strings.TrimLeft(text[len(css):], "\n")
+	}
+	return text
+}
+
+// blocks converts wikitext line by line
+func (r *reverser) blocks(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var out []string
+	inBody := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Page metadata before the body and categories anywhere
+		if !inBody {
+			if m := wikiDisplayTitleRegex.FindStringSubmatch(trimmed); m != nil {
+				r.title = unescapeTemplate(m[1])
+				continue
+			}
+			if m := wikiTemplateCallRegex.FindStringSubmatch(trimmed); m != nil && r.isInfobox(m[1]) {
+				r.parseInfobox(m[2])
+				continue
+			}
+		}
+		if m := wikiCategoryRegex.FindStringSubmatch(trimmed); m != nil {
+			r.categories = append(r.categories, strings.TrimSpace(m[1]))
+			continue
+		}
+		if trimmed == "" {
+			out = append(out, "")
+			continue
+		}
+		inBody = true
+
+		switch {
+		case strings.HasPrefix(line, "<syntaxhighlight"):
+			end := codeBlockEnd(lines, i)
+			out = append(out, r.codeBlock(lines[i:end+1], ""))
+			i = end
+
+		case wikiCodeTitleRegex.MatchString(trimmed) && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "<syntaxhighlight"):
+			title := codeTitle(wikiCodeTitleRegex.FindStringSubmatch(trimmed)[1])
+			end := codeBlockEnd(lines, i+1)
+			out = append(out, r.codeBlock(lines[i+1:end+1], title))
+			i = end
+
+		case r.isCalloutTemplate(line):
+			end := templateEnd(lines, i)
+			out = append(out, r.calloutTemplate(lines[i:end+1]))
+			i = end
+
+		case trimmed == "<blockquote>":
+			end := quoteEnd(lines, i)
+			out = append(out, r.quote(lines[i:end+1]))
+			i = end
+
+		case wikiQuoteLineRegex.MatchString(trimmed):
+			out = append(out, r.quote(lines[i:i+1]))
+
+		case strings.HasPrefix(line, "{|"):
+			end := tableEnd(lines, i)
+			out = append(out, r.table(lines[i:min(end+1, len(lines))]))
+			i = end
+
+		case wikiHeadingRegex.MatchString(line):
+			m := wikiHeadingRegex.FindStringSubmatch(line)
+			if len(m[1]) != len(m[3]) {
+				out = append(out, r.inline(line))
+				break
+			}
+			content := m[2]
+			if sm := wikiHeadingSpanRegex.FindStringSubmatch(content); sm != nil {
+				content = sm[1]
+			}
+			// The References section is rebuilt from the footnotes
+			if content == "References" && r.nextIsReferences(lines, i) {
+				continue
+			}
+			out = append(out, strings.Repeat("#", len(m[1]))+" "+r.inline(content))
+
+		case wikiReferencesRegex.MatchString(trimmed):
+			continue
+
+		case trimmed == "----":
+			out = append(out, "---")
+
+		// The task summary is written again from the list
+		case r.config.Tasks.Summary && wikiTaskSummaryRegex.MatchString(trimmed) &&
+			i+1 < len(lines) && (wikiListRegex.MatchString(lines[i+1]) || isHTMLListTag(lines[i+1])):
+			continue
+
+		case trimmed == "<ol>" || trimmed == "<ul>":
+			end := htmlListEnd(lines, i)
+			out = append(out, r.htmlList(lines[i:end+1]))
+			i = end
+
+		case wikiListRegex.MatchString(line):
+			end := r.listEnd(lines, i)
+			out = append(out, r.list(lines[i:end+1]))
+			i = end
+
+		case wikiDefinitionRegex.MatchString(line):
+			end := i
+			for end+1 < len(lines) && wikiDefinitionRegex.MatchString(lines[end+1]) {
+				end++
+			}
+			out = append(out, r.definitionList(lines[i:end+1]))
+			i = end
+
+		case wikiAttributionRegex.MatchString(trimmed):
+			m := wikiAttributionRegex.FindStringSubmatch(trimmed)
+			out = append(out, "— "+r.inline(m[1]))
+
+		case strings.HasPrefix(line, ">"):
+			content := strings.TrimPrefix(strings.TrimPrefix(line, ">"), " ")
+			out = append(out, strings.TrimRight("> "+r.inline(content), " "))
+
+		default:
+			out = append(out, r.paragraphLine(line))
+		}
+	}
+
+	return collapseBlankLines(out)
+}
+
+// tableEnd returns the index of the |} line closing the table that starts
+// at lines[start], skipping the tables nested in it
+func tableEnd(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "{|"):
+			depth++
+		case trimmed == "|}":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(lines)
+}
+
+// collapseBlankLines joins lines, keeping at most one blank line in a row
+func collapseBlankLines(lines []string) string {
+	var kept []string
+	for _, line := range lines {
+		if line == "" && (len(kept) == 0 || kept[len(kept)-1] == "") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	for len(kept) > 0 && kept[len(kept)-1] == "" {
+		kept = kept[:len(kept)-1]
+	}
+	return strings.Join(kept, "\n")
+}
+
+// nextIsReferences reports whether the next non-blank line after i is a
+// <references/> tag
+func (r *reverser) nextIsReferences(lines []string, i int) bool {
+	for j := i + 1; j < len(lines); j++ {
+		if trimmed := strings.TrimSpace(lines[j]); trimmed != "" {
+			return wikiReferencesRegex.MatchString(trimmed)
+		}
+	}
+	return false
+}
+
+// paragraphLine converts a line of a paragraph, turning <br/> line ends
+// back into hard breaks
+func (r *reverser) paragraphLine(line string) string {
+	if strings.HasSuffix(line, "<br/>") {
+		return r.inline(strings.TrimSuffix(line, "<br/>")) + "\\"
+	}
+	return r.inline(line)
+}
+
+// table converts a wikitable, or a callout box rendered as one
+func (r *reverser) table(lines []string) string {
+	if m := wikiCalloutRegex.FindStringSubmatch(strings.Join(lines, "\n")); m != nil {
+		if kind, title, ok := calloutTypeForTitle(r.config.theme(), m[2]); ok {
+			marker := "> [!" + kind + "]"
+			switch {
+			case strings.Contains(m[1], "mw-collapsed"):
+				marker += "-"
+			case m[1] != "":
+				marker += "+"
+			}
+			if title != "" {
+				marker += " " + r.inline(title)
+			}
+			return strings.Join(append([]string{marker}, r.calloutContent(m[3])...), "\n")
+		}
+	}
+
+	style := tableStyle{}
+	if m := wikiTableClassRegex.FindStringSubmatch(lines[0]); m != nil {
+		for _, class := range strings.Fields(m[1]) {
+			switch class {
+			case "sortable":
+				style.Sortable = true
+			case "mw-collapsible":
+				style.Collapsible = true
+			case "mw-collapsed":
+				style.Collapsed = true
+			}
+		}
+	}
+	if m := wikiTableWidthRegex.FindStringSubmatch(lines[0]); m != nil {
+		style.Width = strings.TrimSpace(m[1])
+	}
+
+	var rows [][]string
+	var markers [][]byte // Whether each cell was a ! or | cell
+	var align []string   // Column alignments of the first row
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "|}":
+		case strings.HasPrefix(trimmed, "|+"):
+			style.caption = strings.ReplaceAll(r.inline(strings.TrimSpace(trimmed[2:])), "&#124;", "|")
+		case strings.HasPrefix(trimmed, "|-"):
+			rows = append(rows, nil)
+			markers = append(markers, nil)
+		case strings.HasPrefix(trimmed, "!"), strings.HasPrefix(trimmed, "|"):
+			if len(rows) == 0 {
+				rows = append(rows, nil)
+				markers = append(markers, nil)
+			}
+			separator := "||"
+			if trimmed[0] == '!' {
+				separator = "!!"
+			}
+			for _, cell := range strings.Split(trimmed[1:], separator) {
+				alignment := ""
+				if m := wikiCellAttrsRegex.FindStringSubmatch(cell); m != nil {
+					if a := wikiTextAlignRegex.FindStringSubmatch(m[1]); a != nil {
+						alignment = a[1]
+					}
+					cell = m[2]
+				}
+				if len(rows) == 1 {
+					align = append(align, alignment)
+				}
+				rows[len(rows)-1] = append(rows[len(rows)-1], r.tableCell(cell))
+				markers[len(markers)-1] = append(markers[len(markers)-1], trimmed[0])
+			}
+		}
+	}
+
+	// Markdown tables need a header row
+	var kept [][]string
+	var keptMarkers [][]byte
+	for i, row := range rows {
+		if row != nil {
+			kept = append(kept, row)
+			keptMarkers = append(keptMarkers, markers[i])
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	header := !strings.Contains(string(keptMarkers[0]), "|")
+	data := keptMarkers
+	if header {
+		data = data[1:]
+	}
+	// Rows that start with a header cell followed by data cells
+	style.HeaderColumn = len(data) > 0
+	for _, m := range data {
+		if m[0] != '!' || !strings.Contains(string(m), "|") {
+			style.HeaderColumn = false
+		}
+	}
+	if !header {
+		kept = append([][]string{make([]string, len(kept[0]))}, kept...)
+	}
+	columns := len(kept[0])
+
+	out := make([]string, 0, len(kept)+1)
+	for i, row := range kept {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		out = append(out, "| "+strings.Join(row[:columns], " | ")+" |")
+		if i == 0 {
+			delimiter := "|"
+			for c := 0; c < columns; c++ {
+				alignment := ""
+				if c < len(align) {
+					alignment = align[c]
+				}
+				delimiter += " " + alignmentDelimiter(alignment) + " |"
+			}
+			out = append(out, delimiter)
+		}
+	}
+	if hint := style.hint(r.config.Tables); hint != "" {
+		out = append([]string{hint}, out...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// alignmentDelimiter writes a delimiter row cell for a column alignment
+func alignmentDelimiter(alignment string) string {
+	switch alignment {
+	case "left":
+		return ":---"
+	case "center":
+		return ":---:"
+	case "right":
+		return "---:"
+	}
+	return "---"
+}
+
+func (r *reverser) tableCell(cell string) string {
+	cell = strings.ReplaceAll(r.inline(strings.TrimSpace(cell)), "&#124;", "|")
+	return strings.ReplaceAll(cell, "|", `\|`)
+}
+
+// calloutTypeForTitle finds the callout type rendered with the given title.
+// A title that is not a type's label is a custom title after the type's
+// emoji, which is returned without it.
+func calloutTypeForTitle(theme *Theme, title string) (kind, custom string, ok bool) {
+	kinds := theme.calloutKinds()
+	for _, kind := range kinds {
+		if theme.callout(kind).title() == title {
+			return kind, "", true
+		}
+	}
+	for _, kind := range kinds {
+		if emoji := theme.callout(kind).Emoji; emoji != "" && strings.HasPrefix(title, emoji+" ") {
+			return kind, strings.TrimPrefix(title, emoji+" "), true
+		}
+	}
+	return "", "", false
+}
+
+// list converts a run of MediaWiki list lines to nested Markdown lists
+func (r *reverser) list(lines []string) string {
+	var out []string
+	counters := make([]int, 0, 4)
+	previous := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// A table or callout between items belongs to the item before it
+		if end := r.nestedBoxEnd(lines, i); end >= 0 {
+			out = append(out, "")
+			for _, l := range strings.Split(r.blocks(strings.Join(lines[i:end+1], "\n")), "\n") {
+				out = append(out, strings.TrimRight(listIndent(previous)+l, " "))
+			}
+			i = end
+			continue
+		}
+
+		m := wikiListRegex.FindStringSubmatch(line)
+		markers, continued, content := m[1], m[2] == ":", m[3]
+		if task, ok := r.taskItem(content); ok && !continued {
+			content = task
+		} else if m := wikiQuoteLineRegex.FindStringSubmatch(content); m != nil && continued {
+			content = strings.TrimRight("> "+r.inline(m[1]), " ")
+		} else {
+			content = r.inline(content)
+		}
+
+		// Continuation lines are later paragraphs of the enclosing item
+		if continued {
+			out = append(out, "", listIndent(markers)+content)
+			continue
+		}
+
+		// A new list starts when the markers of an item change
+		depth := len(markers)
+		if len(counters) > depth {
+			counters = counters[:depth]
+		}
+		if !strings.HasPrefix(previous, markers) && len(counters) == depth {
+			counters = counters[:depth-1]
+		}
+		for len(counters) < depth {
+			counters = append(counters, 0)
+		}
+		counters[depth-1]++
+		previous = markers
+
+		marker := "-"
+		if markers[depth-1] == '#' {
+			marker = fmt.Sprintf("%d.", counters[depth-1])
+		}
+		out = append(out, strings.TrimRight(listIndent(markers[:depth-1])+marker+" "+content, " "))
+	}
+	return strings.Join(out, "\n")
+}
+
+// taskItem converts the content of a list item starting with a checkbox
+func (r *reverser) taskItem(content string) (string, bool) {
+	checked, rest, ok := r.config.Tasks.parseCheckbox(content)
+	if !ok {
+		return "", false
+	}
+	if checked {
+		return "[x] " + r.inline(rest), true
+	}
+	return "[ ] " + r.inline(rest), true
+}
+
+// htmlList converts <ol> and <ul> elements written by renderHTMLList to
+// nested Markdown lists
+func (r *reverser) htmlList(lines []string) string {
+	var out []string
+	markers := ""
+	var counters []int
+	bare := false // the last item has nothing on its marker line
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "<ol>":
+			markers += "#"
+			counters = append(counters, 0)
+		case trimmed == "<ul>":
+			markers += "*"
+			counters = append(counters, 0)
+		case (trimmed == "</ol>" || trimmed == "</ul>") && markers != "":
+			markers = markers[:len(markers)-1]
+			counters = counters[:len(counters)-1]
+		case trimmed == "</li>":
+		case strings.HasPrefix(trimmed, "<li>") && markers != "":
+			content := strings.TrimSuffix(strings.TrimPrefix(trimmed, "<li>"), "</li>")
+			if task, ok := r.taskItem(content); ok {
+				content = task
+			} else {
+				content = r.inline(content)
+			}
+			depth := len(markers)
+			counters[depth-1]++
+			marker := "-"
+			if markers[depth-1] == '#' {
+				marker = fmt.Sprintf("%d.", counters[depth-1])
+			}
+			out = append(out, strings.TrimRight(listIndent(markers[:depth-1])+marker+" "+content, " "))
+			bare = content == ""
+		default:
+			// The other blocks of the item, up to the next list tag
+			end := i
+			for j := i; j < len(lines) && !isHTMLListTag(lines[j]); j++ {
+				if box := r.nestedBoxEnd(lines, j); box >= 0 {
+					j = box
+				} else if strings.HasPrefix(lines[j], "<syntaxhighlight") {
+					j = codeBlockEnd(lines, j)
+				}
+				end = j
+			}
+			// An item that starts with a block has it right below the marker
+			if !bare {
+				out = append(out, "")
+			}
+			bare = false
+			for _, l := range strings.Split(r.blocks(strings.Join(lines[i:end+1], "\n")), "\n") {
+				out = append(out, strings.TrimRight(listIndent(markers)+l, " "))
+			}
+			i = end
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// isHTMLListTag reports whether a line is one of the list tags written by
+// renderHTMLList
+func isHTMLListTag(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	switch trimmed {
+	case "<ol>", "<ul>", "</ol>", "</ul>", "</li>":
+		return true
+	}
+	return strings.HasPrefix(trimmed, "<li>")
+}
+
+// htmlListEnd returns the index of the line closing the <ol> or <ul> that
+// starts at lines[start], skipping the lists nested in it
+func htmlListEnd(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		switch strings.TrimSpace(lines[i]) {
+		case "<ol>", "<ul>":
+			depth++
+		case "</ol>", "</ul>":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(lines) - 1
+}
+
+// listEnd returns the index of the last line of the list that starts at
+// lines[start], including the tables and callouts written between its items
+func (r *reverser) listEnd(lines []string, start int) int {
+	end := start
+	for end+1 < len(lines) {
+		if box := r.nestedBoxEnd(lines, end+1); box >= 0 {
+			end = box
+		} else if wikiListRegex.MatchString(lines[end+1]) {
+			end++
+		} else {
+			break
+		}
+	}
+	return end
+}
+
+// nestedBoxEnd returns the index of the last line of the table or callout
+// template starting at lines[i], or -1 if none starts there
+func (r *reverser) nestedBoxEnd(lines []string, i int) int {
+	switch {
+	case strings.HasPrefix(lines[i], "{|"):
+		return min(tableEnd(lines, i), len(lines)-1)
+	case r.isCalloutTemplate(lines[i]):
+		return templateEnd(lines, i)
+	}
+	return -1
+}
+
+// listIndent is the indentation of content inside the given list markers
+func listIndent(markers string) string {
+	width := 0
+	for _, c := range markers {
+		if c == '#' {
+			width += 3
+		} else {
+			width += 2
+		}
+	}
+	return strings.Repeat(" ", width)
+}
+
+// hold stores converted text behind a placeholder so later rewrites leave
+// it alone
+func (r *reverser) hold(s string) string {
+	r.placeholder = append(r.placeholder, s)
+	return fmt.Sprintf("\x00%d\x00", len(r.placeholder)-1)
+}
+
+// inline converts the inline markup of a line
+func (r *reverser) inline(s string) string {
+	s = wikiMarkedCodeRegex.ReplaceAllString(s, "$1")
+	s = wikiCodeRegex.ReplaceAllStringFunc(s, func(match string) string {
+		code := wikiCodeRegex.FindStringSubmatch(match)[1]
+		code = strings.TrimSuffix(strings.TrimPrefix(code, "<nowiki>"), "</nowiki>")
+		return r.hold(codeSpan(strings.ReplaceAll(code, "&#124;", "|")))
+	})
+	s = wikiNowikiRegex.ReplaceAllStringFunc(s, func(match string) string {
+		return r.hold(escapeMarkdown(wikiNowikiRegex.FindStringSubmatch(match)[1]))
+	})
+
+	s = wikiRefRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := wikiRefRegex.FindStringSubmatch(match)
+		name, content := m[1], m[2]
+		if name == "" {
+			return r.hold("^[" + r.inline(content) + "]")
+		}
+		label := strings.TrimPrefix(name, "fn")
+		if label == "" || strings.Trim(label, "0123456789") != "" {
+			label = name
+		}
+		if !r.refNames[name] && strings.HasSuffix(match, "</ref>") {
+			r.refNames[name] = true
+			r.footnotes = append(r.footnotes, "[^"+label+"]: "+r.inline(strings.ReplaceAll(content, "<br/>", "\n    ")))
+		}
+		return r.hold("[^" + label + "]")
+	})
+
+	s = wikiFileLinkRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := wikiFileLinkRegex.FindStringSubmatch(match)
+		name := r.fileName(m[1])
+		size, alt := "", ""
+		for _, option := range strings.Split(strings.TrimPrefix(m[2], "|"), "|") {
+			switch {
+			case option == "":
+			case strings.HasSuffix(option, "px") && imageSizeRegex.MatchString(strings.TrimSuffix(option, "px")):
+				size = strings.TrimSuffix(option, "px")
+			default:
+				alt = strings.NewReplacer("&#124;", "|", "&#91;", `\[`, "&#93;", `\]`).Replace(option)
+			}
+		}
+		if alt == "" {
+			if size != "" {
+				return r.hold("![[" + name + "|" + size + "]]")
+			}
+			return r.hold("![[" + name + "]]")
+		}
+		if size != "" {
+			alt += "|" + size
+		}
+		return r.hold("![" + alt + "](" + strings.ReplaceAll(name, " ", "%20") + ")")
+	})
+	s = wikiMediaLinkRegex.ReplaceAllStringFunc(s, func(match string) string {
+		return r.hold("![[" + r.fileName(wikiMediaLinkRegex.FindStringSubmatch(match)[1]) + "]]")
+	})
+	s = wikiTransclRegex.ReplaceAllStringFunc(s, func(match string) string {
+		return r.hold("![[" + r.pageName(wikiTransclRegex.FindStringSubmatch(match)[1]) + "]]")
+	})
+	s = wikiInternalRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := wikiInternalRegex.FindStringSubmatch(match)
+		page, heading, _ := strings.Cut(m[1], "#")
+		page = r.pageName(page)
+		target := page
+		if heading != "" {
+			target += "#" + heading
+		}
+		text := m[2]
+		defaultText := page
+		switch {
+		case page == "":
+			defaultText = heading
+		case heading != "":
+			defaultText = page + " > " + heading
+		}
+		if text == "" || text == defaultText || text == target {
+			return r.hold("[[" + target + "]]")
+		}
+		return r.hold("[[" + target + "|" + text + "]]")
+	})
+	s = wikiExternalRegex.ReplaceAllStringFunc(s, func(match string) string {
+		m := wikiExternalRegex.FindStringSubmatch(match)
+		if m[2] == "" {
+			return r.hold("<" + m[1] + ">")
+		}
+		return "[" + m[2] + "](" + m[1] + ")"
+	})
+
+	s = wikiBoldItalicRegex.ReplaceAllString(s, "***$1***")
+	s = wikiBoldRegex.ReplaceAllString(s, "**$1**")
+	s = wikiItalicRegex.ReplaceAllString(s, "*$1*")
+	s = wikiMarkRegex.ReplaceAllString(s, "==$1==")
+	s = wikiStrikeRegex.ReplaceAllString(s, "~~$1~~")
+	s = strings.ReplaceAll(s, "<br/>", "<br>")
+	s = strings.NewReplacer("&lt;", `\<`, "&#39;", "'").Replace(s)
+
+	for wikiPlaceholder.MatchString(s) {
+		s = wikiPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+			var i int
+			fmt.Sscanf(wikiPlaceholder.FindStringSubmatch(match)[1], "%d", &i)
+			return r.placeholder[i]
+		})
+	}
+	return s
+}
+
+// escapeMarkdown backslash-escapes the characters of literal text that
+// Markdown could read as markup
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune("\\`*_[]#-+<>~=!|", c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// codeSpan wraps code in enough backticks to hold the backticks it contains
+func codeSpan(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return fence + " " + code + " " + fence
+	}
+	return fence + code + fence
+}
+
+// pageName undoes the namespace prefix added to wikilink page titles
+func (r *reverser) pageName(title string) string {
+	if ns := strings.TrimSuffix(r.config.WikiLinks.Namespace, ":"); ns != "" {
+		title = strings.TrimPrefix(title, ns+":")
+	}
+	return strings.TrimSpace(title)
+}
+
+// fileName undoes the prefix added to wiki file names
+func (r *reverser) fileName(name string) string {
+	return strings.TrimPrefix(strings.TrimSpace(name), r.config.Files.Prefix)
+}
+
+func (r *reverser) frontMatterConfig() *FrontMatterConfig {
+	if r.config.FrontMatter != nil {
+		return r.config.FrontMatter
+	}
+	return DefaultFrontMatterConfig()
+}
+
+func (r *reverser) isInfobox(name string) bool {
+	template := r.frontMatterConfig().Template
+	return template != "" && strings.TrimSpace(name) == template
+}
+
+// parseInfobox reads the key=value parameters of an infobox call
+func (r *reverser) parseInfobox(params string) {
+	for _, param := range strings.Split(params, "|") {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			r.params = append(r.params, [2]string{strings.TrimSpace(key), unescapeTemplate(strings.TrimSpace(value))})
+		}
+	}
+}
+
+func unescapeTemplate(s string) string {
+	return strings.ReplaceAll(s, "{{!}}", "|")
+}
+
+// frontMatter rebuilds YAML front matter from the page metadata
+func (r *reverser) frontMatter() string {
+	config := r.frontMatterConfig()
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value *yaml.Node) {
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	if r.title != "" && config.TitleKey != "" {
+		add(config.TitleKey, &yaml.Node{Kind: yaml.ScalarNode, Value: r.title})
+	}
+	if len(r.categories) > 0 && len(config.CategoryKeys) > 0 {
+		tags := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, category := range r.categories {
+			tags.Content = append(tags.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: category})
+		}
+		add(config.CategoryKeys[0], tags)
+	}
+	for _, param := range r.params {
+		add(param[0], &yaml.Node{Kind: yaml.ScalarNode, Value: param[1]})
+	}
+	if len(mapping.Content) == 0 {
+		return ""
+	}
+
+	data, err := yaml.Marshal(mapping)
+	if err != nil {
+		return ""
+	}
+	return "---\n" + string(data) + "---"
+}
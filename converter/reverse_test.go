@@ -0,0 +1,199 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Styled Heading",
+			input:    "==<span style=\"color:#021e57;\">Setup</span>==",
+			expected: "## Setup",
+		},
+		{
+			name:     "Plain Heading",
+			input:    "=== Details ===",
+			expected: "### Details",
+		},
+		{
+			name:     "Styled Inline Code",
+			input:    "Run <code style=\"" + inlineCodeStyle + "\">go test</code> now",
+			expected: "Run `go test` now",
+		},
+		{
+			name:     "Inline Code With Backticks",
+			input:    "<code>a`b</code>",
+			expected: "``a`b``",
+		},
+		{
+			name:     "Emphasis",
+			input:    "'''bold''', ''italic'' and '''''both'''''",
+			expected: "**bold**, *italic* and ***both***",
+		},
+		{
+			name:     "Highlight And Strikethrough",
+			input:    "<mark style=\"background-color:#f5ff56\">key</mark> and <s>old</s>",
+			expected: "==key== and ~~old~~",
+		},
+		{
+			name:     "Code Block",
+			input:    "<syntaxhighlight lang=\"go\" line>\nfmt.Println(\"hi\")\n</syntaxhighlight>",
+			expected: "```go\nfmt.Println(\"hi\")\n```",
+		},
+		{
+			name:     "Code Block Without Language",
+			input:    "<syntaxhighlight lang=\"text\" line>\nplain\n</syntaxhighlight>",
+			expected: "```\nplain\n```",
+		},
+		{
+			name:     "Code Block Containing Fence",
+			input:    "<syntaxhighlight lang=\"markdown\" line>\n```\n</syntaxhighlight>",
+			expected: "````markdown\n```\n````",
+		},
+		{
+			name:     "Callout",
+			input:    "{| class=\"wikitable\" style=\"border-left:4px solid #e6a700; background-color:#fff8e6; width:100%;\"\n| <div style=\"padding:0.5em;\">\n<strong style=\"color:#8a6500;\">⚠️ Warning:</strong> Back up first<br/>Then '''upgrade'''\n</div>\n|}",
+			expected: "> [!warning]\n> Back up first\n> Then **upgrade**",
+		},
+		{
+			name:     "Table",
+			input:    "{| class=\"wikitable\"\n|-\n! Name\n! Value\n|-\n| a&#124;b\n| <code>x|y</code>\n|}",
+			expected: "| Name | Value |\n| --- | --- |\n| a\\|b | `x\\|y` |",
+		},
+		{
+			name:     "Aligned Table",
+			input:    "{| class=\"wikitable\"\n|-\n! style=\"text-align:left;\" | Item\n! Qty\n! style=\"text-align:right;\" | [[Price|cost]]\n|-\n| style=\"text-align:left;\" | <nowiki>-</nowiki>1\n| 2\n| style=\"text-align:right;\" | 3\n|}",
+			expected: "| Item | Qty | [[Price\\|cost]] |\n| :--- | --- | ---: |\n| \\-1 | 2 | 3 |",
+		},
+		{
+			name:     "Table Without Header",
+			input:    "{| class=\"wikitable\"\n|-\n| 1 || 2\n|}",
+			expected: "|  |  |\n| --- | --- |\n| 1 | 2 |",
+		},
+		{
+			name:     "Nested Lists",
+			input:    "* one\n** nested\n*# first\n*# second\n* two",
+			expected: "- one\n  - nested\n  1. first\n  2. second\n- two",
+		},
+		{
+			name:     "List Continuation",
+			input:    "# step\n#: more about it\n# next",
+			expected: "1. step\n\n   more about it\n2. next",
+		},
+		{
+			name:     "Horizontal Rule",
+			input:    "above\n\n----\n\nbelow",
+			expected: "above\n\n---\n\nbelow",
+		},
+		{
+			name:     "Hard Break",
+			input:    "one<br/>\ntwo",
+			expected: "one\\\ntwo",
+		},
+		{
+			name:     "External Links",
+			input:    "[https://example.com Example] and [https://example.com]",
+			expected: "[Example](https://example.com) and <https://example.com>",
+		},
+		{
+			name:     "Internal Links",
+			input:    "[[Setup Guide]], [[Setup Guide#Install|Setup Guide > Install]] and [[Other|shown]]",
+			expected: "[[Setup Guide]], [[Setup Guide#Install]] and [[Other|shown]]",
+		},
+		{
+			name:     "Files And Transclusion",
+			input:    "[[File:diagram.png|300px|Flow]] [[File:logo.png]] [[Media:spec.pdf|spec.pdf]]\n{{:Shared Note}}",
+			expected: "![Flow|300](diagram.png) ![[logo.png]] ![[spec.pdf]]\n![[Shared Note]]",
+		},
+		{
+			name:     "File Caption With Brackets",
+			input:    "[[File:pic.png|a &#93;&#93; b &#91;c]]",
+			expected: "![a \\]\\] b \\[c](pic.png)",
+		},
+		{
+			name:     "Footnotes",
+			input:    "A<ref name=\"fn1\">First.</ref> B<ref name=\"fn1\" /> C<ref>Inline.</ref>\n\n==<span style=\"color:#021e57;\">References</span>==\n\n<references/>",
+			expected: "A[^1] B[^1] C^[Inline.]\n\n[^1]: First.",
+		},
+		{
+			name:     "Page Metadata",
+			input:    "{{DISPLAYTITLE:Guide}}\n{{DocInfo|owner=Team {{!}} A}}\n\nBody\n\n[[Category:docs]]\n[[Category:api]]",
+			expected: "---\ntitle: Guide\ntags: [docs, api]\nowner: Team | A\n---\n\nBody",
+		},
+		{
+			name:     "Styling CSS",
+			input:    GetCodeStylingCSS() + "\n\nBody",
+			expected: "Body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ToMarkdown(tt.input, Config{})
+			if result != tt.expected {
+				t.Errorf("ToMarkdown() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToMarkdownLinkMapping(t *testing.T) {
+	config := Config{
+		WikiLinks: WikiLinkConfig{Namespace: "Docs"},
+		Files:     FileConfig{Prefix: "Vault-"},
+	}
+	input := "[[Docs:Setup]] and [[File:Vault-a.png|Alt]]"
+	expected := "[[Setup]] and ![Alt](a.png)"
+	if result := ToMarkdown(input, config); result != expected {
+		t.Errorf("ToMarkdown() = %q, want %q", result, expected)
+	}
+}
+
+// Converting the Markdown written by ToMarkdown again must give the same
+// wikitext
+func TestToMarkdownRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Document",
+			input: "# Title\n\nSome **bold**, *italic*, `code|x`, ==marked== and ~~gone~~ text with [a link](https://example.com).\n\n## Steps\n\n1. First\n2. Second\n   - detail\n\n```bash\nmake build\n```\n",
+		},
+		{
+			name:  "Callouts And Tables",
+			input: "> [!tip]\n> Use the **cache**\n> when possible\n\n| Flag | Meaning |\n|------|---------|\n| `-a\\|b` | either |\n",
+		},
+		{
+			name:  "Titled And Foldable Callouts",
+			input: "> [!summary]- Key points: *all* of them\n> Read these first\n\n> [!faq]+\n> Asked often\n",
+		},
+		{
+			name:  "Callout With Blocks",
+			input: "> [!example]- Steps\n> - one\n> - two\n>\n> > [!warning]+\n> > - nested\n",
+		},
+		{
+			name:  "Links Footnotes And Metadata",
+			input: "---\ntitle: Guide\ntags: [docs]\nowner: Team\n---\nSee [[Setup#Install]] and ![[diagram.png|200]]. Claim[^1] and more^[inline note].\n\n[^1]: Source.\n",
+		},
+		{
+			name:  "Changelog",
+			input: "### Changelog\n\n#### Version 1.0\n\n- First\n\n#### Version 2.0\n\n- Second\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wikitext := Convert(tt.input, Config{}).Text
+			markdown := ToMarkdown(wikitext, Config{})
+			if again := Convert(markdown, Config{}).Text; again != wikitext {
+				t.Errorf("round trip through %q:\ngot  %q\nwant %q", markdown, again, wikitext)
+			}
+		})
+	}
+}
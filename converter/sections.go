@@ -0,0 +1,26 @@
+package converter
+
+// needsSectionRule reports whether n is a major section heading that runs
+// into the content before it without a horizontal rule
+func needsSectionRule(n *Node) bool {
+	if n.Kind != HeadingNode || n.Level != 2 || n.Prev == nil {
+		return false
+	}
+	switch n.Prev.Kind {
+	case ThematicBreakNode, HeadingNode, FrontMatterNode:
+		return false
+	}
+	return true
+}
+
+// sectionRules puts a horizontal rule before every major section heading
+// that needs one
+func sectionRules(doc *Node) {
+	for n := doc.FirstChild; n != nil; n = n.Next {
+		if needsSectionRule(n) {
+			rule := NewNode(ThematicBreakNode)
+			rule.Line = n.Line
+			n.InsertBefore(rule)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TableConfig sets the default style of converted tables. A hint comment
+// right above a table overrides it for that table:
+//
+//	<!-- table: sortable collapsed header-column caption="API methods" width=80% -->
+//
+// Options are turned off with a no- prefix, e.g. no-sortable.
+type TableConfig struct {
+	Sortable     bool   // Let readers sort by a column (class "sortable")
+	Collapsible  bool   // Add a show/hide toggle (class "mw-collapsible")
+	Collapsed    bool   // Start collapsed; implies Collapsible
+	HeaderColumn bool   // Write the first cell of each row as a header cell
+	Width        string // CSS width of every table, e.g. "100%" (default: as wide as the content)
+}
+
+// Validate checks that the width is a CSS length or percentage
+func (c TableConfig) Validate() error {
+	if c.Width != "" && !cssWidthRegex.MatchString(c.Width) {
+		return fmt.Errorf("table width %q is not a CSS length or percentage", c.Width)
+	}
+	return nil
+}
+
+// tableStyle is the style of one table
+type tableStyle struct {
+	TableConfig
+	caption string
+}
+
+var (
+	tableHintRegex   = regexp.MustCompile(`(?s)^<!--\s*table:(.*?)-->$`)
+	tableOptionRegex = regexp.MustCompile(`([\w-]+)(?:=(?:"((?:[^"\\]|\\.)*)"|(\S+)))?`)
+
+	// CSS lengths and percentages a table width may be given in
+	cssWidthRegex = regexp.MustCompile(`^(?:\d+(?:\.\d+)?|\.\d+)(?:%|px|em|rem|ex|ch|vw|vh|vmin|vmax|cm|mm|in|pt|pc)$|^0$|^auto$`)
+
+	// Backslash escapes of quoted hint values
+	hintValueUnescaper = strings.NewReplacer(`\"`, `"`, `\\`, `\`)
+	hintValueEscaper   = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+
+	// The class and width of a {| line, for reverse conversion
+	wikiTableClassRegex = regexp.MustCompile(`class="([^"]*)"`)
+	wikiTableWidthRegex = regexp.MustCompile(`width:\s*([^;"]+)`)
+)
+
+// parseTableHint reads a table hint comment on top of the default style
+func parseTableHint(comment string, defaults TableConfig) (tableStyle, bool) {
+	style := tableStyle{TableConfig: defaults}
+	m := tableHintRegex.FindStringSubmatch(strings.TrimSpace(comment))
+	if m == nil {
+		return style, false
+	}
+	for _, option := range tableOptionRegex.FindAllStringSubmatch(m[1], -1) {
+		name, value := option[1], hintValueUnescaper.Replace(option[2])+option[3]
+		on := !strings.HasPrefix(name, "no-")
+		switch strings.TrimPrefix(name, "no-") {
+		case "sortable":
+			style.Sortable = on
+		case "collapsible":
+			style.Collapsible = on
+			if !on {
+				style.Collapsed = false
+			}
+		case "collapsed":
+			// A table that is no longer collapsed stays collapsible
+			style.Collapsible = style.Collapsible || style.Collapsed
+			style.Collapsed = on
+		case "header-column":
+			style.HeaderColumn = on
+		case "caption":
+			style.caption = value
+		case "width":
+			// A width that is not a CSS length is ignored
+			switch {
+			case !on:
+				style.Width = ""
+			case cssWidthRegex.MatchString(value):
+				style.Width = value
+			}
+		}
+	}
+	return style, true
+}
+
+// tableHint returns the hint comment right above a table, if any
+func tableHint(table *Node) *Node {
+	prev := table.Prev
+	if prev != nil && prev.Kind == HTMLBlockNode && tableHintRegex.MatchString(strings.TrimSpace(prev.Literal)) {
+		return prev
+	}
+	return nil
+}
+
+// isTableHint reports whether a block is a hint comment consumed by the
+// table after it
+func isTableHint(n *Node) bool {
+	return n.Kind == HTMLBlockNode && n.Next != nil && n.Next.Kind == TableNode && tableHint(n.Next) == n
+}
+
+// tableStyleFor returns the style of a table from the defaults and its hint
+func (r *renderer) tableStyleFor(table *Node) tableStyle {
+	if hint := tableHint(table); hint != nil {
+		style, _ := parseTableHint(hint.Literal, r.config.Tables)
+		return style
+	}
+	return tableStyle{TableConfig: r.config.Tables}
+}
+
+// opening writes the {| line of a table and its caption
+func (s tableStyle) opening() []string {
+	class := "wikitable"
+	if s.Sortable {
+		class += " sortable"
+	}
+	if s.Collapsible || s.Collapsed {
+		class += " mw-collapsible"
+	}
+	if s.Collapsed {
+		class += " mw-collapsed"
+	}
+	line := `{| class="` + class + `"`
+	if s.Width != "" && cssWidthRegex.MatchString(s.Width) {
+		line += ` style="width:` + s.Width + `;"`
+	}
+	lines := []string{line}
+	if s.caption != "" {
+		lines = append(lines, "|+ "+strings.ReplaceAll(escapeText(s.caption), "|", "&#124;"))
+	}
+	return lines
+}
+
+// hint writes a table hint comment for the options of s that differ from
+// the defaults, or "" if there are none
+func (s tableStyle) hint(defaults TableConfig) string {
+	var options []string
+	flag := func(name string, value, def bool) {
+		switch {
+		case value && !def:
+			options = append(options, name)
+		case !value && def:
+			options = append(options, "no-"+name)
+		}
+	}
+	flag("sortable", s.Sortable, defaults.Sortable)
+	if s.Collapsed && !defaults.Collapsed {
+		options = append(options, "collapsed")
+	} else {
+		collapsible := s.Collapsible || s.Collapsed
+		flag("collapsible", collapsible, defaults.Collapsible || defaults.Collapsed)
+		if collapsible {
+			flag("collapsed", s.Collapsed, defaults.Collapsed)
+		}
+	}
+	flag("header-column", s.HeaderColumn, defaults.HeaderColumn)
+	if s.caption != "" {
+		options = append(options, `caption="`+hintValueEscaper.Replace(s.caption)+`"`)
+	}
+	switch {
+	case s.Width == defaults.Width:
+	case s.Width == "":
+		options = append(options, "no-width")
+	default:
+		options = append(options, "width="+s.Width)
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return "<!-- table: " + strings.Join(options, " ") + " -->"
+}
@@ -0,0 +1,139 @@
+package converter
+
+import "testing"
+
+func TestConvertTableStyles(t *testing.T) {
+	const table = "| Method | Use |\n|---|---|\n| GET | Read |\n| PUT | Write |"
+
+	tests := []struct {
+		name     string
+		input    string
+		config   TableConfig
+		expected string
+	}{
+		{
+			name:     "Default",
+			input:    table,
+			expected: "{| class=\"wikitable\"\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Config",
+			input:    table,
+			config:   TableConfig{Sortable: true, Collapsed: true, HeaderColumn: true, Width: "100%"},
+			expected: "{| class=\"wikitable sortable mw-collapsible mw-collapsed\" style=\"width:100%;\"\n|-\n! Method\n! Use\n|-\n! GET\n| Read\n|-\n! PUT\n| Write\n|}",
+		},
+		{
+			name:     "Hint",
+			input:    "<!-- table: sortable collapsible caption=\"HTTP | methods\" width=50% -->\n" + table,
+			expected: "{| class=\"wikitable sortable mw-collapsible\" style=\"width:50%;\"\n|+ HTTP &#124; methods\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Hint With Escaped Quotes",
+			input:    "<!-- table: caption=\"API \\\"ref\\\" | list\" -->\n" + table,
+			expected: "{| class=\"wikitable\"\n|+ API \"ref\" &#124; list\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Invalid Widths Are Ignored",
+			input:    "<!-- table: width=1px;color:red -->\n" + table,
+			config:   TableConfig{Width: "100%\" onclick=\"x"},
+			expected: "{| class=\"wikitable\"\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Hint Overrides Config",
+			input:    "<!-- table: no-sortable no-collapsed no-width -->\n" + table,
+			config:   TableConfig{Sortable: true, Collapsed: true, Width: "100%"},
+			expected: "{| class=\"wikitable mw-collapsible\"\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Hint Applies To Next Table Only",
+			input:    "<!-- table: sortable -->\n" + table + "\n\n" + table,
+			expected: "{| class=\"wikitable sortable\"\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}\n\n{| class=\"wikitable\"\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Other Comments Are Kept",
+			input:    "<!-- note -->\n" + table,
+			expected: "<!-- note -->\n\n{| class=\"wikitable\"\n|-\n! Method\n! Use\n|-\n| GET\n| Read\n|-\n| PUT\n| Write\n|}",
+		},
+		{
+			name:     "Header Column Escapes Separators",
+			input:    "| a | b |\n|---|---|\n| x!!y | z |",
+			config:   TableConfig{HeaderColumn: true},
+			expected: "{| class=\"wikitable\"\n|-\n! a\n! b\n|-\n! x<nowiki>!!</nowiki>y\n| z\n|}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{Tables: tt.config}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTableStylesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		config TableConfig
+	}{
+		{
+			name:  "Hint",
+			input: "<!-- table: sortable collapsed header-column caption=\"API methods\" width=80% -->\n| Method | Use |\n| --- | --- |\n| GET | Read |",
+		},
+		{
+			name:  "Caption With Quotes",
+			input: "<!-- table: caption=\"API \\\"ref\\\" list\" -->\n| Method | Use |\n| --- | --- |\n| GET | Read |",
+		},
+		{
+			name:   "Config",
+			input:  "| Method | Use |\n| --- | --- |\n| GET | Read |",
+			config: TableConfig{Sortable: true, Width: "100%"},
+		},
+		{
+			name:   "Hint Against Config",
+			input:  "<!-- table: no-sortable -->\n| Method | Use |\n| --- | --- |\n| GET | Read |",
+			config: TableConfig{Sortable: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Tables: tt.config}
+			wikitext := Convert(tt.input, config).Text
+			markdown := ToMarkdown(wikitext, config)
+			if markdown != tt.input {
+				t.Errorf("ToMarkdown() = %q, want %q", markdown, tt.input)
+			}
+			if again := Convert(markdown, config).Text; again != wikitext {
+				t.Errorf("round trip through %q:\ngot  %q\nwant %q", markdown, again, wikitext)
+			}
+		})
+	}
+}
+
+func TestTableConfigValidate(t *testing.T) {
+	tests := []struct {
+		width string
+		valid bool
+	}{
+		{width: "", valid: true},
+		{width: "100%", valid: true},
+		{width: "40em", valid: true},
+		{width: "12.5rem", valid: true},
+		{width: "auto", valid: true},
+		{width: "100", valid: false},
+		{width: "50%;color:red", valid: false},
+		{width: `1px" onclick="x`, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.width, func(t *testing.T) {
+			err := TableConfig{Width: tt.width}.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
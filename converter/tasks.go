@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TaskConfig sets how task list items (- [ ] and - [x]) are written
+type TaskConfig struct {
+	Template string // Checkbox template, e.g. "Checkbox" for {{Checkbox}} and {{Checkbox|checked}} (default: ☐ and ☑)
+	Summary  bool   // Write a "3/7 done" line above each task list
+}
+
+// Checkboxes written when no template is set. PrettifyCheckmarks turns the
+// ticked one into ✅.
+const (
+	taskBoxOpen = "☐"
+	taskBoxDone = "☑"
+)
+
+var (
+	taskMarkerRegex      = regexp.MustCompile(`^\[([ xX])\][ \t]+`)
+	wikiTaskSummaryRegex = regexp.MustCompile(`^''\d+/\d+ done''$`)
+)
+
+// checkbox writes the checkbox of a task list item
+func (c TaskConfig) checkbox(checked bool) string {
+	switch {
+	case c.Template != "" && checked:
+		return "{{" + c.Template + "|checked}}"
+	case c.Template != "":
+		return "{{" + c.Template + "}}"
+	case checked:
+		return taskBoxDone
+	}
+	return taskBoxOpen
+}
+
+// parseCheckbox reads the checkbox at the start of a converted list item
+// and returns the rest of the item
+func (c TaskConfig) parseCheckbox(s string) (checked bool, rest string, ok bool) {
+	boxes := map[string]bool{taskBoxOpen: false, taskBoxDone: true, "✅": true}
+	if c.Template != "" {
+		boxes[c.checkbox(false)] = false
+		boxes[c.checkbox(true)] = true
+	}
+	for box, checked := range boxes {
+		if rest, found := strings.CutPrefix(s, box); found && (rest == "" || rest[0] == ' ') {
+			return checked, strings.TrimPrefix(rest, " "), true
+		}
+	}
+	return false, s, false
+}
+
+// taskCount counts the task items of a list and of the lists nested in it
+func taskCount(list *Node) (done, total int) {
+	Walk(list, func(n *Node, entering bool) WalkStatus {
+		if entering && n.Kind == ListItemNode && n.Task {
+			total++
+			if n.Checked {
+				done++
+			}
+		}
+		return WalkContinue
+	})
+	return done, total
+}
+
+// taskSummary writes the "3/7 done" line above a task list, or "" if the
+// summary is off or the list has no tasks
+func (r *renderer) taskSummary(list *Node) string {
+	if !r.config.Tasks.Summary {
+		return ""
+	}
+	done, total := taskCount(list)
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("''%d/%d done''\n", done, total)
+}
@@ -0,0 +1,74 @@
+package converter
+
+import "testing"
+
+func TestConvertTaskLists(t *testing.T) {
+	const tasks = "- [x] Parse\n- [ ] Render\n  - [x] Lists\n  - [ ] Tables\n- Notes"
+
+	plain := DefaultPipeline()
+	_ = plain.Disable(PassPrettifyCheckmarks)
+
+	tests := []struct {
+		name     string
+		input    string
+		config   Config
+		expected string
+	}{
+		{
+			name:     "Checkboxes",
+			input:    tasks,
+			config:   Config{Pipeline: plain},
+			expected: "* ☑ Parse\n* ☐ Render\n** ☑ Lists\n** ☐ Tables\n* Notes",
+		},
+		{
+			name:     "Prettified",
+			input:    tasks,
+			expected: "* ✅ Parse\n* ☐ Render\n** ✅ Lists\n** ☐ Tables\n* Notes",
+		},
+		{
+			name:     "Template",
+			input:    tasks,
+			config:   Config{Tasks: TaskConfig{Template: "Checkbox"}},
+			expected: "* {{Checkbox|checked}} Parse\n* {{Checkbox}} Render\n** {{Checkbox|checked}} Lists\n** {{Checkbox}} Tables\n* Notes",
+		},
+		{
+			name:     "Summary",
+			input:    tasks + "\n\nText\n\n- plain",
+			config:   Config{Tasks: TaskConfig{Summary: true}},
+			expected: "''2/4 done''\n* ✅ Parse\n* ☐ Render\n** ✅ Lists\n** ☐ Tables\n* Notes\n\nText\n\n* plain",
+		},
+		{
+			name:     "Ordered",
+			input:    "1. [ ] First\n2. [x] Second",
+			expected: "# ☐ First\n# ✅ Second",
+		},
+		{
+			name:     "Not Tasks",
+			input:    "- [ ]missing space\n- text [ ] later\n\n[x] outside a list",
+			expected: "* [ ]missing space\n* text [ ] later\n\n[x] outside a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, tt.config).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTaskListsRoundTrip(t *testing.T) {
+	const tasks = "- [x] Parse\n- [ ] Render *lists*\n  - [x] Nested"
+
+	for _, config := range []Config{
+		{},
+		{Tasks: TaskConfig{Template: "Checkbox", Summary: true}},
+	} {
+		wikitext := Convert(tasks, config).Text
+		if markdown := ToMarkdown(wikitext, config); markdown != tasks {
+			t.Errorf("ToMarkdown(%q) = %q, want %q", wikitext, markdown, tasks)
+		}
+	}
+}
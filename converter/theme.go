@@ -0,0 +1,389 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Theme holds the colors and CSS used in converted pages
+type Theme struct {
+	Name          string                  `yaml:"name" json:"name"`
+	HeadingColors map[int]string          `yaml:"headingColors" json:"headingColors"` // By level 1-6; a missing level is not colored
+	Code          CodeColors              `yaml:"code" json:"code"`                   // Inline code
+	Highlight     string                  `yaml:"highlight" json:"highlight"`         // ==highlight== background; "" for a plain <mark>
+	Callouts      map[string]CalloutStyle `yaml:"callouts" json:"callouts"`           // By callout type
+	CSS           string                  `yaml:"css" json:"css"`                     // Template for the --with-css header, executed with the theme
+}
+
+// CodeColors are the colors of inline code; when both are empty code is
+// written as a plain <code> tag
+type CodeColors struct {
+	Background string `yaml:"background" json:"background"`
+	Color      string `yaml:"color" json:"color"`
+}
+
+// CalloutStyle describes how one Obsidian callout type is rendered. Empty
+// colors are left out of the box's style.
+type CalloutStyle struct {
+	Emoji      string `yaml:"emoji" json:"emoji"`
+	Label      string `yaml:"label" json:"label"`
+	Border     string `yaml:"border" json:"border"`
+	Background string `yaml:"background" json:"background"`
+	Text       string `yaml:"text" json:"text"`
+}
+
+// Built-in theme names
+const (
+	ThemeTieto = "tieto"
+	ThemePlain = "plain"
+)
+
+// DefaultTheme returns the Tieto theme used when Config.Theme is nil
+func DefaultTheme() *Theme {
+	theme := &Theme{
+		Name:          ThemeTieto,
+		HeadingColors: make(map[int]string, len(headingColors)),
+		Code:          CodeColors{Background: "#f5ff56", Color: "#021e57"},
+		Highlight:     "#f5ff56",
+		Callouts:      make(map[string]CalloutStyle, len(calloutStyles)),
+		CSS:           tietoCSS,
+	}
+	for level, color := range headingColors {
+		theme.HeadingColors[level] = color
+	}
+	for kind, style := range calloutStyles {
+		theme.Callouts[kind] = style
+	}
+	return theme
+}
+
+// PlainTheme returns a theme without colors or CSS, for idiomatic wikitext
+// that follows the wiki's own skin
+func PlainTheme() *Theme {
+	theme := &Theme{
+		Name:          ThemePlain,
+		HeadingColors: map[int]string{},
+		Callouts:      make(map[string]CalloutStyle, len(calloutStyles)),
+	}
+	for kind, style := range calloutStyles {
+		theme.Callouts[kind] = CalloutStyle{Emoji: style.Emoji, Label: style.Label}
+	}
+	return theme
+}
+
+// BuiltinTheme returns the built-in theme with the given name
+func BuiltinTheme(name string) (*Theme, bool) {
+	switch strings.ToLower(name) {
+	case ThemeTieto:
+		return DefaultTheme(), true
+	case ThemePlain:
+		return PlainTheme(), true
+	}
+	return nil, false
+}
+
+// LoadTheme reads a theme from a YAML or JSON file (by extension). The file
+// starts from the built-in theme named by its "base" key, Tieto by default,
+// and overrides what it sets; a callout entry replaces the whole style of
+// that type.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	unmarshal := yaml.Unmarshal
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		unmarshal = json.Unmarshal
+	}
+
+	var header struct {
+		Base string `yaml:"base" json:"base"`
+	}
+	if err := unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("theme %s: %w", path, err)
+	}
+	base := header.Base
+	if base == "" {
+		base = ThemeTieto
+	}
+	theme, ok := BuiltinTheme(base)
+	if !ok {
+		return nil, fmt.Errorf("theme %s: unknown base theme %q (use %q or %q)", path, base, ThemeTieto, ThemePlain)
+	}
+	theme.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := unmarshal(data, theme); err != nil {
+		return nil, fmt.Errorf("theme %s: %w", path, err)
+	}
+	if err := theme.Validate(); err != nil {
+		return nil, fmt.Errorf("theme %s: %w", path, err)
+	}
+	return theme, nil
+}
+
+// Validate checks the heading levels, callout types and CSS template, and
+// that every color pair meets WCAG AA contrast
+func (t *Theme) Validate() error {
+	for level := range t.HeadingColors {
+		if level < 1 || level > 6 {
+			return fmt.Errorf("heading level %d is not between 1 and 6", level)
+		}
+	}
+	var unknown []string
+	for kind := range t.Callouts {
+		if _, ok := calloutStyles[kind]; !ok {
+			unknown = append(unknown, kind)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown callout type %s", strings.Join(unknown, ", "))
+	}
+	if _, err := t.executeCSS(); err != nil {
+		return fmt.Errorf("css: %w", err)
+	}
+	if failing := t.CheckContrast(); len(failing) > 0 {
+		return &ContrastError{Pairs: failing}
+	}
+	return nil
+}
+
+// StylingCSS renders the theme's CSS template, or returns "" when the theme
+// has none or the template fails
+func (t *Theme) StylingCSS() string {
+	css, err := t.executeCSS()
+	if err != nil {
+		return ""
+	}
+	return css
+}
+
+func (t *Theme) executeCSS() (string, error) {
+	if t.CSS == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("css").Option("missingkey=zero").Parse(t.CSS)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, t); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// codeStyle is the style attribute value of inline code
+func (t *Theme) codeStyle() string {
+	if t.Code.Background == "" && t.Code.Color == "" {
+		return ""
+	}
+	var b strings.Builder
+	if t.Code.Background != "" {
+		b.WriteString("background-color:" + t.Code.Background + ";")
+	}
+	if t.Code.Color != "" {
+		b.WriteString("color:" + t.Code.Color + ";")
+	}
+	b.WriteString("padding:2px 6px;border-radius:3px;font-family:Consolas,Monaco,monospace;")
+	return b.String()
+}
+
+// codeTag opens an inline code element
+func (t *Theme) codeTag() string {
+	if style := t.codeStyle(); style != "" {
+		return `<code style="` + style + `">`
+	}
+	return "<code>"
+}
+
+// markTag opens a highlight element
+func (t *Theme) markTag() string {
+	if t.Highlight != "" {
+		return `<mark style="background-color:` + t.Highlight + `">`
+	}
+	return "<mark>"
+}
+
+// heading renders a heading line, wrapping the content in a colored span
+// when the theme colors that level
+func (t *Theme) heading(level int, content string) string {
+	equals := strings.Repeat("=", level)
+	if color := t.HeadingColors[level]; color != "" {
+		return fmt.Sprintf(`%s<span style="color:%s;">%s</span>%s`, equals, color, content, equals)
+	}
+	return equals + " " + content + " " + equals
+}
+
+// callout returns the style of a callout type, falling back to the Tieto
+// style for types the theme leaves out
+func (t *Theme) callout(kind string) CalloutStyle {
+	if style, ok := t.Callouts[kind]; ok {
+		return style
+	}
+	return calloutStyles[kind]
+}
+
+// title is the label line of a callout, e.g. "📝 Note"
+func (s CalloutStyle) title() string {
+	if s.Emoji == "" {
+		return s.Label
+	}
+	return s.Emoji + " " + s.Label
+}
+
+// tietoCSS is the Tieto CSS template: accessible syntax highlighting plus
+// the theme's code and heading colors
+const tietoCSS = `<div style="display:none;">
+<!-- Accessible Syntax Highlighting (WCAG AA compliant) -->
+<style>
+/* Code block container */
+.mw-highlight {
+    background-color: #FAFAFA !important;
+    border: 1px solid #CCCCCC !important;
+    border-left: 3px solid #0000FF !important;
+    padding: 1em !important;
+    border-radius: 4px;
+    font-family: 'Consolas', 'Monaco', 'Courier New', monospace !important;
+    font-size: 0.95em !important;
+    line-height: 1.5 !important;
+}
+
+/* Syntax highlighting - Industry standard accessible colors */
+.mw-highlight .c,   /* Comments */
+.mw-highlight .cm,  /* Multi-line comments */
+.mw-highlight .c1 { /* Single-line comments */
+    color: #696C77 !important;
+    font-style: italic !important;
+}
+
+.mw-highlight .k,   /* Keywords */
+.mw-highlight .kd,  /* Keyword declarations */
+.mw-highlight .kn,  /* Keyword namespace */
+.mw-highlight .kp,  /* Keyword pseudo */
+.mw-highlight .kr,  /* Keyword reserved */
+.mw-highlight .kt { /* Keyword type */
+    color: #0000FF !important;
+    font-weight: 600 !important;
+}
+
+.mw-highlight .s,   /* Strings */
+.mw-highlight .s1,  /* Single-quoted strings */
+.mw-highlight .s2,  /* Double-quoted strings */
+.mw-highlight .sb,  /* String backtick */
+.mw-highlight .sc { /* String char */
+    color: #387A37 !important;
+}
+
+.mw-highlight .m,   /* Numbers */
+.mw-highlight .mf,  /* Float */
+.mw-highlight .mi,  /* Integer */
+.mw-highlight .mo,  /* Octal */
+.mw-highlight .mh { /* Hex */
+    color: #A626A4 !important;
+}
+
+.mw-highlight .n,   /* Names/variables */
+.mw-highlight .nv { /* Variable */
+    color: #383A42 !important;
+}
+
+.mw-highlight .nf,  /* Function name */
+.mw-highlight .fm { /* Function magic */
+    color: #3260D0 !important;
+    font-weight: 500 !important;
+}
+
+.mw-highlight .o,   /* Operators */
+.mw-highlight .ow { /* Operator word */
+    color: #383A42 !important;
+}
+
+/* XML/JSON specific */
+.mw-highlight .nt { /* XML/HTML tags */
+    color: #C43E2F !important;
+    font-weight: 600 !important;
+}
+
+.mw-highlight .na { /* XML/HTML attributes */
+    color: #986801 !important;
+}
+
+.mw-highlight .p { /* Punctuation */
+    color: #383A42 !important;
+}
+
+/* Line numbers */
+.mw-highlight .linenos {
+    background-color: #F0F0F0 !important;
+    color: #666669 !important;
+    padding-right: 1em !important;
+    padding-left: 0.5em !important;
+    border-right: 1px solid #CCCCCC !important;
+    user-select: none !important;
+}
+
+/* Boolean/Null/Constants */
+.mw-highlight .kc,  /* Constant */
+.mw-highlight .bp { /* Builtin pseudo */
+    color: #A626A4 !important;
+}
+
+/* Inline code styling */
+code {
+    background-color: {{.Code.Background}} !important;
+    color: {{.Code.Color}} !important;
+    padding: 2px 6px !important;
+    border-radius: 3px !important;
+    font-family: 'Consolas', 'Monaco', 'Courier New', monospace !important;
+}
+
+/* Heading colors */
+.mw-parser-output h1,
+h1.firstHeading {
+    color: {{index .HeadingColors 1}} !important;
+    border-bottom: 2px solid {{index .HeadingColors 1}} !important;
+    font-weight: 600 !important;
+}
+
+.mw-parser-output h2,
+h2 {
+    color: {{index .HeadingColors 2}} !important;
+    border-bottom: 1px solid {{index .HeadingColors 2}} !important;
+    font-weight: 600 !important;
+}
+
+.mw-parser-output h3,
+h3 {
+    color: {{index .HeadingColors 3}} !important;
+    font-weight: 600 !important;
+}
+
+.mw-parser-output h4,
+h4 {
+    color: {{index .HeadingColors 4}} !important;
+    font-weight: 600 !important;
+}
+
+.mw-parser-output h5,
+h5 {
+    color: {{index .HeadingColors 5}} !important;
+    font-weight: 600 !important;
+}
+
+.mw-parser-output h6,
+h6 {
+    color: {{index .HeadingColors 6}} !important;
+    font-weight: 600 !important;
+}
+</style>
+</div>
+
+`
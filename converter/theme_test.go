@@ -0,0 +1,151 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlainTheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Heading",
+			input:    "## Setup",
+			expected: "== Setup ==",
+		},
+		{
+			name:     "Inline Code And Highlight",
+			input:    "Run `make` and ==look==",
+			expected: "Run <code>make</code> and <mark>look</mark>",
+		},
+		{
+			name:     "Callout",
+			input:    "> [!tip]\n> Use the cache",
+			expected: "{| class=\"wikitable\" style=\"width:100%;\"\n| <div style=\"padding:0.5em;\">\n<strong>💡 Tip:</strong><br/>Use the cache\n</div>\n|}",
+		},
+		{
+			name:     "References Heading",
+			input:    "Claim[^a]\n\n[^a]: Source.",
+			expected: "Claim<ref name=\"a\">Source.</ref>\n\n== References ==\n\n<references/>",
+		},
+		{
+			name:     "Changelog",
+			input:    "### Changelog\n\n#### Version 1.0\n\nOld\n\n#### Version 2.0\n\nNew\n\n## Next\n\nText",
+			expected: "=== Changelog ===\n\n==== Version 2.0 ====\n\nNew\n\n==== Version 1.0 ====\n\nOld\n\n== Next ==\n\nText",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Convert(tt.input, Config{Theme: PlainTheme(), AddStyling: true}).Text
+			if result != tt.expected {
+				t.Errorf("Convert().Text = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlainThemeRoundTrip(t *testing.T) {
+	config := Config{Theme: PlainTheme()}
+	input := "# Title\n\n> [!warning]\n> Careful with `rm` and ==this==\n"
+	wikitext := Convert(input, config).Text
+	markdown := ToMarkdown(wikitext, config)
+	if again := Convert(markdown, config).Text; again != wikitext {
+		t.Errorf("round trip through %q:\ngot  %q\nwant %q", markdown, again, wikitext)
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		check   func(t *testing.T, theme *Theme)
+		wantErr string
+	}{
+		{
+			name:    "YAML Overrides Tieto",
+			file:    "brand.yaml",
+			content: "headingColors:\n  1: \"#112233\"\nhighlight: \"#ffee00\"\ncallouts:\n  note: {emoji: \"🗒️\", label: Memo, border: \"#000000\"}\n",
+			check: func(t *testing.T, theme *Theme) {
+				if theme.Name != "brand" || theme.HeadingColors[1] != "#112233" || theme.HeadingColors[2] != "#021e57" {
+					t.Errorf("heading colors = %v", theme.HeadingColors)
+				}
+				if theme.Highlight != "#ffee00" || theme.Code.Background != "#f5ff56" {
+					t.Errorf("highlight = %q, code = %+v", theme.Highlight, theme.Code)
+				}
+				if theme.Callouts["note"].title() != "🗒️ Memo" || theme.Callouts["tip"].Label != "Tip" {
+					t.Errorf("callouts = %+v", theme.Callouts)
+				}
+			},
+		},
+		{
+			name:    "JSON Based On Plain",
+			file:    "docs.json",
+			content: `{"base": "plain", "code": {"color": "#333333"}, "css": "<style>code { color: {{.Code.Color}}; }</style>"}`,
+			check: func(t *testing.T, theme *Theme) {
+				if len(theme.HeadingColors) != 0 || theme.codeStyle() != "color:#333333;padding:2px 6px;border-radius:3px;font-family:Consolas,Monaco,monospace;" {
+					t.Errorf("theme = %+v", theme)
+				}
+				if css := theme.StylingCSS(); css != "<style>code { color: #333333; }</style>" {
+					t.Errorf("StylingCSS() = %q", css)
+				}
+			},
+		},
+		{
+			name:    "Unknown Base",
+			file:    "bad.yaml",
+			content: "base: neon\n",
+			wantErr: `unknown base theme "neon"`,
+		},
+		{
+			name:    "Invalid Heading Level",
+			file:    "bad.yaml",
+			content: "headingColors:\n  7: \"#000000\"\n",
+			wantErr: "heading level 7",
+		},
+		{
+			name:    "Unknown Callout Type",
+			file:    "bad.yaml",
+			content: "callouts:\n  hazard: {label: Hazard}\n",
+			wantErr: "unknown callout type hazard",
+		},
+		{
+			name:    "Low Contrast",
+			file:    "bad.yaml",
+			content: "headingColors:\n  3: \"#dddddd\"\n",
+			wantErr: "heading 3: #dddddd on #ffffff is 1.36:1, needs 4.5:1",
+		},
+		{
+			name:    "Broken CSS Template",
+			file:    "bad.yaml",
+			content: "css: \"{{.Nope}}\"\n",
+			wantErr: "css:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			theme, err := LoadTheme(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadTheme() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, theme)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package converter
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TitleCase selects how page titles from wikilinks are capitalised
+type TitleCase string
+
+// Supported title case normalisations
+const (
+	CaseKeep       TitleCase = ""      // Leave titles as written
+	CaseFirstUpper TitleCase = "first" // Capitalise the first letter, like MediaWiki
+	CaseLower      TitleCase = "lower" // Lowercase the whole title
+	CaseTitle      TitleCase = "title" // Capitalise every word
+)
+
+// SpaceMode selects how spaces and underscores in page titles are written
+type SpaceMode string
+
+// Supported space handling modes
+const (
+	SpacesKeep        SpaceMode = ""            // Leave titles as written
+	SpacesSpaces      SpaceMode = "spaces"      // Write underscores as spaces
+	SpacesUnderscores SpaceMode = "underscores" // Write spaces as underscores
+)
+
+// WikiLinkConfig controls how Obsidian [[wikilinks]] and relative links to
+// Markdown files map to MediaWiki page titles
+type WikiLinkConfig struct {
+	Namespace    string    // Prefix for every page title, e.g. "Docs" gives [[Docs:Page]]
+	Case         TitleCase // Capitalisation applied to page titles
+	Spaces       SpaceMode // Space/underscore handling in page titles
+	StripFolders bool      // Drop vault folders, so [[notes/Page]] links to Page
+}
+
+var wikiLinkRegex = regexp.MustCompile(`^\[\[([^\[\]\n|]+)(?:\|([^\[\]\n]*))?\]\]`)
+
+// PageTitle maps a vault note name to a wiki page title
+func (c WikiLinkConfig) PageTitle(name string) string {
+	name = strings.TrimSpace(strings.TrimSuffix(name, ".md"))
+	if c.StripFolders {
+		name = path.Base(name)
+	}
+
+	switch c.Case {
+	case CaseFirstUpper:
+		name = upperFirst(name)
+	case CaseLower:
+		name = strings.ToLower(name)
+	case CaseTitle:
+		name = titleCase(name)
+	}
+
+	switch c.Spaces {
+	case SpacesSpaces:
+		name = strings.ReplaceAll(name, "_", " ")
+	case SpacesUnderscores:
+		name = strings.ReplaceAll(name, " ", "_")
+	}
+
+	if c.Namespace != "" && name != "" {
+		name = strings.TrimSuffix(c.Namespace, ":") + ":" + name
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// titleCase capitalises the first letter of every word and path segment
+func titleCase(s string) string {
+	var b strings.Builder
+	wordStart := true
+	for _, r := range s {
+		if wordStart {
+			r = unicode.ToUpper(r)
+		}
+		wordStart = r == ' ' || r == '_' || r == '/'
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parseWikiLink recognises an Obsidian [[Page|alias]] link
+func (p *inlineParser) parseWikiLink(block *Node) bool {
+	m := wikiLinkRegex.FindStringSubmatch(p.subject[p.pos:])
+	if m == nil || strings.TrimSpace(m[1]) == "" {
+		return false
+	}
+	p.pos += len(m[0])
+	link := &Node{Kind: WikiLinkNode, Destination: strings.TrimSpace(m[1])}
+	if alias := strings.TrimSpace(m[2]); alias != "" {
+		link.AppendChild(newText(alias))
+	}
+	block.AppendChild(link)
+	return true
+}
+
+// splitTarget separates a link target into page and heading. Obsidian block
+// references (#^id) have no wiki equivalent and are dropped.
+func splitTarget(target string) (page, heading string) {
+	page, heading, _ = strings.Cut(target, "#")
+	if strings.HasPrefix(heading, "^") {
+		heading = ""
+	}
+	return strings.TrimSpace(page), strings.TrimSpace(heading)
+}
+
+// internalLink builds a MediaWiki internal link for a page and heading,
+// adding display text only when it differs from the link target
+func (r *renderer) internalLink(page, heading, text string) string {
+	target := ""
+	if page != "" {
+		target = r.config.WikiLinks.PageTitle(page)
+	}
+	if heading != "" {
+		target += "#" + heading
+	}
+	// A link to a heading of this page shows the heading by itself
+	if text == "" || text == target || (page == "" && text == heading) {
+		return "[[" + target + "]]"
+	}
+	return "[[" + target + "|" + text + "]]"
+}
+
+// renderWikiLink renders an Obsidian wikilink. Without an alias the link
+// shows the note name the way Obsidian does, e.g. "Page > Heading".
+func (r *renderer) renderWikiLink(n *Node) string {
+	page, heading := splitTarget(n.Destination)
+	text := r.renderInlines(n, " ")
+	if text == "" {
+		switch {
+		case page == "":
+			text = heading
+		case heading == "":
+			text = strings.TrimSuffix(page, ".md")
+		default:
+			text = strings.TrimSuffix(page, ".md") + " > " + heading
+		}
+	}
+	return r.internalLink(page, heading, text)
+}
+
+// renderLocalLink renders a Markdown link to another note or to a heading
+// of this page, returning false for targets that are not Markdown notes
+func (r *renderer) renderLocalLink(n *Node) (string, bool) {
+	dest, err := url.PathUnescape(n.Destination)
+	if err != nil {
+		dest = n.Destination
+	}
+	page, heading := splitTarget(dest)
+	if page != "" && !strings.HasSuffix(strings.ToLower(page), ".md") {
+		return "", false
+	}
+	if page == "" && heading == "" {
+		return "", false
+	}
+	if text, ok := r.headingSlugs[heading]; ok && page == "" {
+		heading = text
+	}
+	return r.internalLink(page, heading, r.renderInlines(n, " ")), true
+}
+
+// collectHeadingSlugs maps GitHub-style anchors such as "heading-level-2"
+// to the heading text MediaWiki uses for its anchors
+func collectHeadingSlugs(doc *Node) map[string]string {
+	slugs := make(map[string]string)
+	Walk(doc, func(n *Node, entering bool) WalkStatus {
+		if entering && n.Kind == HeadingNode {
+			text := strings.TrimSpace(n.TextContent())
+			if slug := headingSlug(text); slug != "" {
+				if _, exists := slugs[slug]; !exists {
+					slugs[slug] = text
+				}
+			}
+			return WalkSkipChildren
+		}
+		return WalkContinue
+	})
+	return slugs
+}
+
+// headingSlug computes the anchor GitHub generates for a heading
+func headingSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestPageTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   WikiLinkConfig
+		input    string
+		expected string
+	}{
+		{
+			name:     "Unchanged",
+			config:   WikiLinkConfig{},
+			input:    "release notes",
+			expected: "release notes",
+		},
+		{
+			name:     "Namespace",
+			config:   WikiLinkConfig{Namespace: "Docs"},
+			input:    "Page",
+			expected: "Docs:Page",
+		},
+		{
+			name:     "First Upper",
+			config:   WikiLinkConfig{Case: CaseFirstUpper},
+			input:    "release notes",
+			expected: "Release notes",
+		},
+		{
+			name:     "Title Case With Underscores",
+			config:   WikiLinkConfig{Case: CaseTitle, Spaces: SpacesUnderscores},
+			input:    "release notes",
+			expected: "Release_Notes",
+		},
+		{
+			name:     "Lower With Spaces",
+			config:   WikiLinkConfig{Case: CaseLower, Spaces: SpacesSpaces},
+			input:    "API_Reference",
+			expected: "api reference",
+		},
+		{
+			name:     "Strip Folders And Extension",
+			config:   WikiLinkConfig{StripFolders: true},
+			input:    "projects/alpha/Overview.md",
+			expected: "Overview",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.PageTitle(tt.input)
+			if got != tt.expected {
+				t.Errorf("PageTitle() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertWikiLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   WikiLinkConfig
+		input    string
+		expected string
+	}{
+		{
+			name:     "Page",
+			input:    "[[Page]]",
+			expected: "[[Page]]",
+		},
+		{
+			name:     "Alias",
+			input:    "[[Page|the page]]",
+			expected: "[[Page|the page]]",
+		},
+		{
+			name:     "Page And Heading",
+			input:    "[[Page#Setup]]",
+			expected: "[[Page#Setup|Page > Setup]]",
+		},
+		{
+			name:     "Heading Only",
+			input:    "[[#Setup]]",
+			expected: "[[#Setup]]",
+		},
+		{
+			name:     "Block Reference Dropped",
+			input:    "[[Page#^a1b2c3]]",
+			expected: "[[Page]]",
+		},
+		{
+			name:     "Namespace Keeps Display Text",
+			config:   WikiLinkConfig{Namespace: "Docs"},
+			input:    "[[Page]]",
+			expected: "[[Docs:Page|Page]]",
+		},
+		{
+			name:     "Relative Markdown Link",
+			input:    "[guide](Setup%20Guide.md#Install)",
+			expected: "[[Setup Guide#Install|guide]]",
+		},
+		{
+			name:     "Anchor Link Resolves Heading",
+			input:    "## Getting Started\n\n[start](#getting-started)",
+			expected: "==<span style=\"color:#021e57;\">Getting Started</span>==\n\n[[#Getting Started|start]]",
+		},
+		{
+			name:     "Anchor Link Named After Heading",
+			input:    "## Getting Started\n\n[Getting Started](#getting-started)",
+			expected: "==<span style=\"color:#021e57;\">Getting Started</span>==\n\n[[#Getting Started]]",
+		},
+		{
+			name:     "Wikilink In Code Untouched",
+			input:    "`[[Page]]`",
+			expected: "<code style=\"" + inlineCodeStyle + "\"><nowiki>[[Page]]</nowiki></code>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(tt.input, Config{WikiLinks: tt.config}).Text
+			if got != tt.expected {
+				t.Errorf("Convert().Text = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
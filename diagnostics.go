@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+)
+
+// printDiagnostics prints conversion diagnostics in the file:line:col
+// format of compilers, which editors and CI logs can link to the source
+func printDiagnostics(w io.Writer, file string, diagnostics []converter.Diagnostic) {
+	if file == "-" {
+		file = "<stdin>"
+	}
+	for _, d := range diagnostics {
+		fmt.Fprintf(w, "%s:%s\n", file, d)
+		if d.Suggestion != "" {
+			fmt.Fprintf(w, "%s:%d:%d: note: %s\n", file, d.Line, d.Column, d.Suggestion)
+		}
+	}
+}
+
+// diagnosticFailure tells why diagnostics fail a conversion, or returns ""
+// if they do not. Errors, which mean content was lost, always fail it;
+// warnings only with --strict.
+func diagnosticFailure(diagnostics []converter.Diagnostic, strict bool) string {
+	errors := 0
+	for _, d := range diagnostics {
+		if d.Severity == converter.SeverityError {
+			errors++
+		}
+	}
+	switch {
+	case errors > 0:
+		return fmt.Sprintf("%d error(s)", errors)
+	case strict && len(diagnostics) > 0:
+		return fmt.Sprintf("%d warning(s) with --strict", len(diagnostics))
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+)
+
+func TestDiagnosticFailure(t *testing.T) {
+	warning := converter.Diagnostic{Severity: converter.SeverityWarning, Code: converter.CodeUnclosedFence}
+	failure := converter.Diagnostic{Severity: converter.SeverityError, Code: converter.CodeTableColumns}
+
+	tests := []struct {
+		name        string
+		diagnostics []converter.Diagnostic
+		strict      bool
+		expected    string
+	}{
+		{name: "None", expected: ""},
+		{name: "None Strict", strict: true, expected: ""},
+		{name: "Warning", diagnostics: []converter.Diagnostic{warning}, expected: ""},
+		{name: "Warning Strict", diagnostics: []converter.Diagnostic{warning, warning}, strict: true, expected: "2 warning(s) with --strict"},
+		{name: "Error", diagnostics: []converter.Diagnostic{warning, failure}, expected: "1 error(s)"},
+		{name: "Error Strict", diagnostics: []converter.Diagnostic{warning, failure}, strict: true, expected: "1 error(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnosticFailure(tt.diagnostics, tt.strict)
+			if got != tt.expected {
+				t.Errorf("diagnosticFailure() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
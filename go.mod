@@ -2,4 +2,7 @@ module github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus
 
 go 1.21
 
-require github.com/spf13/pflag v1.0.5
+require (
+	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+	flag "github.com/spf13/pflag"
+)
+
+// lintFile lints one Markdown file, rewriting it first with fix set, and
+// returns the issues left and how many were fixed. Fixed stdin input is
+// written to stdout.
+func lintFile(file string, fix bool) (remaining []converter.Diagnostic, fixed int, err error) {
+	data, err := readInput(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	source := string(data)
+	if !fix {
+		return converter.Lint(source), 0, nil
+	}
+
+	before := len(converter.Lint(source))
+	output, remaining := converter.FixLint(source)
+	switch {
+	case file == "-":
+		fmt.Print(output)
+	case output != source:
+		if err := os.WriteFile(file, []byte(output), 0644); err != nil {
+			return nil, 0, fmt.Errorf("writing file '%s': %w", file, err)
+		}
+	}
+	return remaining, before - len(remaining), nil
+}
+
+// runLint implements the lint subcommand, which reports Markdown patterns
+// that turn out badly in MediaWiki. It returns the process exit code.
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	var fix bool
+	flags.BoolVar(&fix, "fix", false, "Rewrite the files to fix what can be fixed, e.g. wrap underscored identifiers in backticks")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	files := flags.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: lint needs at least one Markdown file")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Usage: md-to-mediawiki-go lint [--fix] <file.md>...")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	issues := 0
+	for _, file := range files {
+		remaining, fixed, err := lintFile(file, fix)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error %v\n", err)
+			return 1
+		}
+		if fixed > 0 {
+			fmt.Fprintf(os.Stderr, "✅ Fixed %d issue(s) in '%s'\n", fixed, file)
+		}
+		printDiagnostics(os.Stderr, file, remaining)
+		issues += len(remaining)
+	}
+	if issues > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunLint(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.md")
+	note := filepath.Join(dir, "note.md")
+	writeTestFile(t, clean, "# Title\n\nUse `A_B`.\n")
+	writeTestFile(t, note, "Set _A_B_\n## Next\n")
+
+	if code := runLint([]string{clean}); code != 0 {
+		t.Errorf("lint clean file exit code = %d, want 0", code)
+	}
+	if code := runLint([]string{clean, note}); code != 1 {
+		t.Errorf("lint exit code = %d, want 1", code)
+	}
+
+	if code := runLint([]string{"--fix", note}); code != 0 {
+		t.Errorf("lint --fix exit code = %d, want 0", code)
+	}
+	got, err := os.ReadFile(note)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Set `_A_B_`\n\n---\n\n## Next\n"; string(got) != expected {
+		t.Errorf("fixed file = %q, want %q", got, expected)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
 	flag "github.com/spf13/pflag"
@@ -12,20 +14,46 @@ import (
 const version = "1.0.0"
 
 func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "convert":
+			os.Exit(runConvert(os.Args[2:]))
+		case "publish":
+			os.Exit(runPublish(os.Args[2:]))
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "callout-template":
+			os.Exit(runCalloutTemplate(os.Args[2:]))
+		}
+	}
+
 	// Define CLI flags
 	var (
 		inputFile   string
 		outputFile  string
-		withCSS     bool
-		concurrent  bool
+		options     conversionOptions
+		listPasses  bool
+		contrast    bool
+		manifest    string
+		assetDir    string
+		watch       bool
+		reverse     bool
+		interval    time.Duration
 		showVersion bool
 		showHelp    bool
 	)
 
-	flag.StringVarP(&inputFile, "input", "i", "", "Input Markdown file")
-	flag.StringVarP(&outputFile, "output", "o", "", "Output MediaWiki file (default: stdout)")
-	flag.BoolVar(&withCSS, "with-css", false, "Include CSS styling in output")
-	flag.BoolVarP(&concurrent, "concurrent", "c", false, "Use concurrent processing for large files (>50KB)")
+	flag.StringVarP(&inputFile, "input", "i", "", "Input Markdown file (MediaWiki file with --reverse)")
+	flag.StringVarP(&outputFile, "output", "o", "", "Output MediaWiki file (Markdown file with --reverse; default: stdout)")
+	flag.BoolVar(&reverse, "reverse", false, "Convert wikitext written by this tool back to Markdown")
+	options.register(flag.CommandLine)
+	flag.StringVar(&manifest, "manifest", "", "Write a JSON manifest of referenced local assets to this file")
+	flag.StringVar(&assetDir, "asset-dir", "", "Folder (e.g. the vault) to search for assets not found next to the input")
+	flag.BoolVarP(&watch, "watch", "w", false, "Keep running and re-convert the input when it changes (needs -o)")
+	flag.DurationVar(&interval, "watch-interval", 500*time.Millisecond, "How often --watch checks for changes")
+	flag.BoolVar(&listPasses, "list-passes", false, "List conversion passes in pipeline order and exit")
+	flag.BoolVar(&contrast, "check-contrast", false, "Check the theme's colors (and the input's inline colors, with -i) against WCAG AA and exit")
 	flag.BoolVarP(&showVersion, "version", "v", false, "Show version information")
 	flag.BoolVarP(&showHelp, "help", "h", false, "Show help information")
 
@@ -38,39 +66,80 @@ func main() {
 		os.Exit(0)
 	}
 
+	config, err := options.config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if listPasses {
+		printPasses(config.Pipeline)
+		os.Exit(0)
+	}
+
+	if contrast {
+		os.Exit(checkContrast(config.Theme, inputFile, config))
+	}
+
 	// Show help
 	if showHelp || inputFile == "" {
 		showUsage()
 		os.Exit(0)
 	}
 
-	// Read input file
-	var inputData []byte
-	var err error
+	if reverse && (watch || manifest != "") {
+		fmt.Fprintln(os.Stderr, "Error: --reverse cannot be combined with --watch or --manifest")
+		os.Exit(1)
+	}
 
-	if inputFile == "-" {
-		// Read from stdin
-		inputData, err = io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
-			os.Exit(1)
-		}
-	} else {
-		// Read from file
-		inputData, err = os.ReadFile(inputFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading file '%s': %v\n", inputFile, err)
+	if watch {
+		if inputFile == "-" || outputFile == "" || outputFile == "-" {
+			fmt.Fprintln(os.Stderr, "Error: --watch needs an input file and an output file (-o)")
 			os.Exit(1)
 		}
+		watchFile(inputFile, outputFile, config, interval, options.strict)
+		os.Exit(0)
+	}
+
+	// Read input file
+	inputData, err := readInput(inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		os.Exit(1)
 	}
 
 	// Convert
-	config := converter.Config{
-		AddStyling: withCSS,
-		Concurrent: concurrent,
+	var output string
+	var diagnostics []converter.Diagnostic
+	if reverse {
+		output = converter.ToMarkdown(string(inputData), config)
+	} else {
+		result := converter.Convert(string(inputData), config)
+		output, diagnostics = result.Text, result.Diagnostics
 	}
+	printDiagnostics(os.Stderr, inputFile, diagnostics)
 
-	output := converter.Convert(string(inputData), config)
+	// Write the asset manifest
+	if manifest != "" {
+		baseDir := "."
+		if inputFile != "-" {
+			baseDir = filepath.Dir(inputFile)
+		}
+		assets := converter.CollectAssets(converter.Parse(string(inputData)), config)
+		entries, err := buildManifest(assets, baseDir, assetDir)
+		if err == nil {
+			err = writeManifest(manifest, entries)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing manifest '%s': %v\n", manifest, err)
+			os.Exit(1)
+		}
+		for _, entry := range entries {
+			if entry.Missing {
+				fmt.Fprintf(os.Stderr, "Warning: asset '%s' not found\n", entry.Source)
+			}
+		}
+	}
 
 	// Write output
 	if outputFile == "" || outputFile == "-" {
@@ -85,15 +154,71 @@ func main() {
 		}
 
 		cssNote := ""
-		if withCSS {
+		if options.withCSS && !reverse {
 			cssNote = " (with CSS)"
 		}
+		if reverse {
+			cssNote = " (to Markdown)"
+		}
 		concurrentNote := ""
-		if concurrent {
+		if options.concurrent && !reverse {
 			concurrentNote = " [concurrent mode]"
 		}
 		fmt.Printf("✅ Converted '%s' -> '%s'%s%s\n", inputFile, outputFile, cssNote, concurrentNote)
 	}
+
+	if failure := diagnosticFailure(diagnostics, options.strict); failure != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", failure)
+		os.Exit(1)
+	}
+}
+
+// readInput reads a file, or stdin when the name is "-"
+func readInput(inputFile string) ([]byte, error) {
+	if inputFile == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading from stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading file '%s': %w", inputFile, err)
+	}
+	return data, nil
+}
+
+// checkContrast reports the color pairs of the theme, and of the inline
+// styles in the converted input if there is one, that fail WCAG AA. It
+// returns the process exit code.
+func checkContrast(theme *converter.Theme, inputFile string, config converter.Config) int {
+	if theme == nil {
+		theme = converter.DefaultTheme()
+	}
+	pairs := theme.ContrastPairs()
+	failing := theme.CheckContrast()
+
+	if inputFile != "" {
+		data, err := readInput(inputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error %v\n", err)
+			return 1
+		}
+		failing = append(failing, converter.CheckWikitextContrast(converter.Convert(string(data), config).Text)...)
+	}
+
+	switch {
+	case len(failing) > 0:
+	case len(pairs) == 0:
+		fmt.Printf("✅ The '%s' theme sets no colors\n", theme.Name)
+		return 0
+	default:
+		fmt.Printf("✅ All %d color pairs of the '%s' theme meet WCAG AA contrast\n", len(pairs), theme.Name)
+		return 0
+	}
+	fmt.Fprintln(os.Stderr, (&converter.ContrastError{Pairs: failing}).Error())
+	return 1
 }
 
 func showUsage() {
@@ -102,6 +227,10 @@ func showUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  md-to-mediawiki-go -i <input.md> [-o <output.txt>] [options]")
+	fmt.Println("  md-to-mediawiki-go convert --input-dir <vault> --output-dir <out> [options]")
+	fmt.Println("  md-to-mediawiki-go publish --api <api.php URL> --user <Name@Bot> [options] <file.md>...")
+	fmt.Println("  md-to-mediawiki-go lint [--fix] <file.md>...")
+	fmt.Println("  md-to-mediawiki-go callout-template [--name Callout] [--theme <theme>] [-o <dir>]")
 	fmt.Println()
 	fmt.Println("Options:")
 	flag.PrintDefaults()
@@ -116,6 +245,37 @@ func showUsage() {
 	fmt.Println("  # Concurrent processing for large files")
 	fmt.Println("  md-to-mediawiki-go -i large-doc.md -o output.txt -c")
 	fmt.Println()
+	fmt.Println("  # Unstyled wikitext that follows the wiki's skin")
+	fmt.Println("  md-to-mediawiki-go -i example.md -o output.txt --theme plain")
+	fmt.Println()
+	fmt.Println("  # Keep the changelog in source order")
+	fmt.Println("  md-to-mediawiki-go -i example.md -o output.txt --disable-pass reverse-changelog")
+	fmt.Println()
+	fmt.Println("  # Find and fix MediaWiki pitfalls before converting")
+	fmt.Println("  md-to-mediawiki-go lint --fix note.md")
+	fmt.Println()
+	fmt.Println("  # Write callouts as {{Callout}} calls and generate the template for the wiki")
+	fmt.Println("  md-to-mediawiki-go -i example.md -o output.txt --callout-template Callout")
+	fmt.Println("  md-to-mediawiki-go callout-template -o wiki-templates/")
+	fmt.Println()
+	fmt.Println("  # Fail in CI when a construct cannot be converted cleanly")
+	fmt.Println("  md-to-mediawiki-go convert --input-dir docs/ --output-dir out/ --strict")
+	fmt.Println()
+	fmt.Println("  # Convert a whole vault, mirroring its folders")
+	fmt.Println("  md-to-mediawiki-go convert --input-dir vault/ --output-dir out/")
+	fmt.Println()
+	fmt.Println("  # List images to upload with the page")
+	fmt.Println("  md-to-mediawiki-go -i note.md -o output.txt --manifest assets.json --asset-dir ~/vault")
+	fmt.Println()
+	fmt.Println("  # Publish to the wiki with a bot password from $MW_BOT_PASSWORD")
+	fmt.Println("  md-to-mediawiki-go publish --api https://wiki.example.com/w/api.php --user Me@Docs note.md")
+	fmt.Println()
+	fmt.Println("  # Turn a page written by this tool back into Markdown")
+	fmt.Println("  md-to-mediawiki-go -i output.txt -o note.md --reverse")
+	fmt.Println()
+	fmt.Println("  # Re-convert on every save")
+	fmt.Println("  md-to-mediawiki-go -i note.md -o output.txt --watch")
+	fmt.Println()
 	fmt.Println("  # Read from stdin, write to stdout")
 	fmt.Println("  cat example.md | md-to-mediawiki-go -i - > output.txt")
 	fmt.Println()
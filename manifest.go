@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+)
+
+// manifestEntry describes one local asset to upload alongside the page
+type manifestEntry struct {
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Size    int64  `json:"size"`
+	SHA1    string `json:"sha1"`
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// buildManifest resolves assets against the input file's folder and then
+// assetDir, where Obsidian-style references may also match by base name.
+// Assets that cannot be found are listed as missing.
+func buildManifest(assets []converter.Asset, baseDir, assetDir string) ([]manifestEntry, error) {
+	entries := make([]manifestEntry, 0, len(assets))
+	for _, asset := range assets {
+		entry := manifestEntry{Source: asset.Source, Target: asset.FileName}
+		source := resolveAsset(asset.Source, baseDir, assetDir)
+		if source == "" {
+			entry.Missing = true
+			entries = append(entries, entry)
+			continue
+		}
+		size, sum, err := hashFile(source)
+		if err != nil {
+			return nil, err
+		}
+		entry.Source, entry.Size, entry.SHA1 = source, size, sum
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// resolveAsset finds the file an asset reference points to, or returns ""
+func resolveAsset(source, baseDir, assetDir string) string {
+	source = filepath.FromSlash(source)
+	if filepath.IsAbs(source) {
+		if isFile(source) {
+			return source
+		}
+		return ""
+	}
+	for _, dir := range []string{baseDir, assetDir} {
+		if dir == "" {
+			continue
+		}
+		if candidate := filepath.Join(dir, source); isFile(candidate) {
+			return candidate
+		}
+	}
+	if assetDir == "" {
+		return ""
+	}
+
+	// Obsidian resolves embeds by file name anywhere in the vault
+	found := ""
+	name := filepath.Base(source)
+	_ = filepath.WalkDir(assetDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && d.Name() == name {
+			found = p
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// hashFile returns the size and hex SHA-1 of a file, as MediaWiki reports
+// them for uploads
+func hashFile(name string) (int64, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeManifest writes the manifest as indented JSON
+func writeManifest(name string, entries []manifestEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
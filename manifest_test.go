@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+)
+
+func TestResolveAsset(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "vault", "notes")
+	assetDir := filepath.Join(dir, "vault")
+	writeTestFile(t, filepath.Join(baseDir, "images", "local.png"), "local")
+	writeTestFile(t, filepath.Join(assetDir, "assets", "shared.png"), "shared")
+	writeTestFile(t, filepath.Join(assetDir, "attachments", "deep", "diagram.png"), "diagram")
+	if err := os.MkdirAll(filepath.Join(baseDir, "folder.png"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	absolute := filepath.Join(assetDir, "assets", "shared.png")
+
+	tests := []struct {
+		name     string
+		source   string
+		assetDir string
+		expected string
+	}{
+		{name: "Next To Input", source: "images/local.png", assetDir: assetDir, expected: filepath.Join(baseDir, "images", "local.png")},
+		{name: "Relative To Asset Dir", source: "assets/shared.png", assetDir: assetDir, expected: absolute},
+		{name: "Base Name Anywhere In Asset Dir", source: "diagram.png", assetDir: assetDir, expected: filepath.Join(assetDir, "attachments", "deep", "diagram.png")},
+		{name: "Base Name Of Wrong Folder", source: "other/diagram.png", assetDir: assetDir, expected: filepath.Join(assetDir, "attachments", "deep", "diagram.png")},
+		{name: "No Base Name Search Without Asset Dir", source: "diagram.png", expected: ""},
+		{name: "Absolute Path", source: absolute, expected: absolute},
+		{name: "Missing Absolute Path", source: filepath.Join(dir, "missing.png"), assetDir: assetDir, expected: ""},
+		{name: "Directory Is Not An Asset", source: "folder.png", expected: ""},
+		{name: "Missing", source: "missing.png", assetDir: assetDir, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveAsset(tt.source, baseDir, tt.assetDir)
+			if got != tt.expected {
+				t.Errorf("resolveAsset(%q) = %q, want %q", tt.source, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		size     int64
+		expected string
+	}{
+		{name: "Content", content: "diagram", size: 7, expected: "8737093597f4fbcf30fb6cb5d44b96f338f143c1"},
+		{name: "Empty", content: "", size: 0, expected: "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(dir, tt.name)
+			writeTestFile(t, name, tt.content)
+			size, sum, err := hashFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size != tt.size || sum != tt.expected {
+				t.Errorf("hashFile() = %d, %q, want %d, %q", size, sum, tt.size, tt.expected)
+			}
+		})
+	}
+
+	if _, _, err := hashFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("hashFile() of a missing file should fail")
+	}
+}
+
+func TestBuildManifest(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "notes")
+	assetDir := filepath.Join(dir, "vault")
+	writeTestFile(t, filepath.Join(baseDir, "logo.png"), "logo")
+	writeTestFile(t, filepath.Join(assetDir, "files", "diagram.png"), "diagram")
+
+	assets := []converter.Asset{
+		{Source: "logo.png", FileName: "Handbook-logo.png"},
+		{Source: "diagram.png", FileName: "diagram.png"},
+		{Source: "gone.png", FileName: "gone.png"},
+	}
+	expected := []manifestEntry{
+		{Source: filepath.Join(baseDir, "logo.png"), Target: "Handbook-logo.png", Size: 4, SHA1: "5807dd602664a565fe53cf2d203674b388d7b2d1"},
+		{Source: filepath.Join(assetDir, "files", "diagram.png"), Target: "diagram.png", Size: 7, SHA1: "8737093597f4fbcf30fb6cb5d44b96f338f143c1"},
+		{Source: "gone.png", Target: "gone.png", Missing: true},
+	}
+
+	got, err := buildManifest(assets, baseDir, assetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("buildManifest() = %+v, want %+v", got, expected)
+	}
+}
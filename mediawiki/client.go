@@ -0,0 +1,291 @@
+// Package mediawiki is a minimal client for the MediaWiki Action API,
+// covering what publishing converted pages needs: bot password login, CSRF
+// tokens, revision lookups and edits.
+package mediawiki
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// ErrEditConflict is returned by Edit when the page changed after the
+// edit's base timestamp, or when a page to be created already exists
+var ErrEditConflict = errors.New("edit conflict")
+
+// APIError is an error reported by the API in its "error" object
+type APIError struct {
+	Code string
+	Info string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mediawiki: %s: %s", e.Code, e.Info)
+}
+
+// Is matches edit conflicts against ErrEditConflict
+func (e *APIError) Is(target error) bool {
+	return target == ErrEditConflict && (e.Code == "editconflict" || e.Code == "articleexists")
+}
+
+// Client talks to one wiki's api.php. Login state is kept in a cookie jar,
+// so a client must not be shared between users.
+type Client struct {
+	apiURL    string
+	http      *http.Client
+	UserAgent string // Sent with every request, as Wikimedia sites require
+}
+
+// NewClient creates a client for the api.php endpoint at apiURL. A nil
+// httpClient uses a default client; in either case a cookie jar is added
+// if the client has none.
+func NewClient(apiURL string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("mediawiki: invalid API URL %q", apiURL)
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	if httpClient.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		copied := *httpClient
+		copied.Jar = jar
+		httpClient = &copied
+	}
+	return &Client{apiURL: apiURL, http: httpClient, UserAgent: "md-to-mediawiki-plus"}, nil
+}
+
+// call sends an API request and decodes the JSON response into v. Requests
+// with a token are POSTed so the token never appears in a URL.
+func (c *Client) call(ctx context.Context, params url.Values, post bool, v interface{}) error {
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+
+	var req *http.Request
+	var err error
+	if post {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mediawiki: %s returned %s", params.Get("action"), resp.Status)
+	}
+
+	var envelope struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("mediawiki: invalid response to %s: %w", params.Get("action"), err)
+	}
+	if envelope.Error != nil {
+		return envelope.Error
+	}
+	return json.Unmarshal(body, v)
+}
+
+// token fetches a token of the given type, e.g. "login" or "csrf"
+func (c *Client) token(ctx context.Context, kind string) (string, error) {
+	var resp struct {
+		Query struct {
+			Tokens map[string]string `json:"tokens"`
+		} `json:"query"`
+	}
+	params := url.Values{"action": {"query"}, "meta": {"tokens"}, "type": {kind}}
+	if err := c.call(ctx, params, false, &resp); err != nil {
+		return "", err
+	}
+	token := resp.Query.Tokens[kind+"token"]
+	if token == "" {
+		return "", fmt.Errorf("mediawiki: no %s token in response", kind)
+	}
+	return token, nil
+}
+
+// Login signs in with a bot password created on Special:BotPasswords,
+// where username has the form "User@BotName"
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	token, err := c.token(ctx, "login")
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Login struct {
+			Result string `json:"result"`
+			Reason string `json:"reason"`
+		} `json:"login"`
+	}
+	params := url.Values{
+		"action":     {"login"},
+		"lgname":     {username},
+		"lgpassword": {password},
+		"lgtoken":    {token},
+	}
+	if err := c.call(ctx, params, true, &resp); err != nil {
+		return err
+	}
+	if resp.Login.Result != "Success" {
+		reason := resp.Login.Reason
+		if reason == "" {
+			reason = resp.Login.Result
+		}
+		return fmt.Errorf("mediawiki: login as %s failed: %s", username, reason)
+	}
+	return nil
+}
+
+// CSRFToken fetches the token edits must carry
+func (c *Client) CSRFToken(ctx context.Context) (string, error) {
+	return c.token(ctx, "csrf")
+}
+
+// Revision describes the latest revision of a page
+type Revision struct {
+	Exists    bool
+	RevID     int
+	Timestamp string // ISO 8601, usable as an edit's BaseTimestamp
+}
+
+// LatestRevision looks up the current revision of a page
+func (c *Client) LatestRevision(ctx context.Context, title string) (Revision, error) {
+	var resp struct {
+		Query struct {
+			Pages []struct {
+				Missing   bool `json:"missing"`
+				Invalid   bool `json:"invalid"`
+				Revisions []struct {
+					RevID     int    `json:"revid"`
+					Timestamp string `json:"timestamp"`
+				} `json:"revisions"`
+			} `json:"pages"`
+		} `json:"query"`
+	}
+	params := url.Values{
+		"action": {"query"},
+		"prop":   {"revisions"},
+		"titles": {title},
+		"rvprop": {"ids|timestamp"},
+	}
+	if err := c.call(ctx, params, false, &resp); err != nil {
+		return Revision{}, err
+	}
+	if len(resp.Query.Pages) == 0 {
+		return Revision{}, fmt.Errorf("mediawiki: no page info for %q", title)
+	}
+	page := resp.Query.Pages[0]
+	if page.Invalid {
+		return Revision{}, fmt.Errorf("mediawiki: invalid page title %q", title)
+	}
+	if page.Missing || len(page.Revisions) == 0 {
+		return Revision{}, nil
+	}
+	return Revision{Exists: true, RevID: page.Revisions[0].RevID, Timestamp: page.Revisions[0].Timestamp}, nil
+}
+
+// EditRequest describes a full-page edit
+type EditRequest struct {
+	Title   string
+	Text    string
+	Summary string
+	Minor   bool
+	Bot     bool // Hide the edit from recent changes; needs the bot right
+
+	// BaseTimestamp is the timestamp of the revision the text was based
+	// on. If the page changed since, the edit fails with ErrEditConflict
+	// instead of overwriting someone else's changes.
+	BaseTimestamp string
+
+	// CreateOnly makes the edit fail with ErrEditConflict if the page
+	// exists, for pages that were missing when the edit was prepared
+	CreateOnly bool
+}
+
+// EditResult is the outcome of a successful edit
+type EditResult struct {
+	Title        string
+	PageID       int
+	OldRevID     int
+	NewRevID     int
+	NewTimestamp string
+	NoChange     bool // The text was identical, so no revision was saved
+}
+
+// Edit saves the text of a page. The client must be logged in; the edit is
+// asserted to come from a user so it is never saved anonymously.
+func (c *Client) Edit(ctx context.Context, token string, edit EditRequest) (*EditResult, error) {
+	params := url.Values{
+		"action":  {"edit"},
+		"title":   {edit.Title},
+		"text":    {edit.Text},
+		"summary": {edit.Summary},
+		"assert":  {"user"},
+		"token":   {token},
+	}
+	if edit.Minor {
+		params.Set("minor", "1")
+	} else {
+		params.Set("notminor", "1")
+	}
+	if edit.Bot {
+		params.Set("bot", "1")
+	}
+	if edit.BaseTimestamp != "" {
+		params.Set("basetimestamp", edit.BaseTimestamp)
+	}
+	if edit.CreateOnly {
+		params.Set("createonly", "1")
+	}
+
+	var resp struct {
+		Edit struct {
+			Result       string `json:"result"`
+			Title        string `json:"title"`
+			PageID       int    `json:"pageid"`
+			OldRevID     int    `json:"oldrevid"`
+			NewRevID     int    `json:"newrevid"`
+			NewTimestamp string `json:"newtimestamp"`
+			NoChange     bool   `json:"nochange"`
+		} `json:"edit"`
+	}
+	if err := c.call(ctx, params, true, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Edit.Result != "Success" {
+		return nil, fmt.Errorf("mediawiki: edit of %q returned %q", edit.Title, resp.Edit.Result)
+	}
+	return &EditResult{
+		Title:        resp.Edit.Title,
+		PageID:       resp.Edit.PageID,
+		OldRevID:     resp.Edit.OldRevID,
+		NewRevID:     resp.Edit.NewRevID,
+		NewTimestamp: resp.Edit.NewTimestamp,
+		NoChange:     resp.Edit.NoChange,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package mediawiki
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/mediawiki/mediawikitest"
+)
+
+func newTestClient(t *testing.T, server *mediawikitest.Server) *Client {
+	t.Helper()
+	client, err := NewClient(server.APIURL(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestLogin(t *testing.T) {
+	server := mediawikitest.NewServer("Docs@publisher", "secret")
+	defer server.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{name: "Valid Bot Password", username: "Docs@publisher", password: "secret"},
+		{name: "Wrong Password", username: "Docs@publisher", password: "nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestClient(t, server).Login(ctx, tt.username, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	server := mediawikitest.NewServer("Docs@publisher", "secret")
+	defer server.Close()
+	ctx := context.Background()
+
+	client := newTestClient(t, server)
+	if err := client.Login(ctx, "Docs@publisher", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	token, err := client.CSRFToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create the page
+	result, err := client.Edit(ctx, token, EditRequest{Title: "Guide", Text: "v1", Summary: "Import", Minor: true, Bot: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := server.Page("Guide")
+	if page == nil || page.Text != "v1" || page.Summary != "Import" || !page.Minor || !page.Bot {
+		t.Fatalf("page after edit = %+v", page)
+	}
+	if result.NewRevID != page.RevID || result.NewTimestamp != page.Timestamp {
+		t.Errorf("Edit() = %+v, want revision %d at %s", result, page.RevID, page.Timestamp)
+	}
+
+	rev, err := client.LatestRevision(ctx, "Guide")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rev.Exists || rev.Timestamp != page.Timestamp {
+		t.Errorf("LatestRevision() = %+v, want timestamp %s", rev, page.Timestamp)
+	}
+
+	// Saving the same text makes no revision
+	result, err = client.Edit(ctx, token, EditRequest{Title: "Guide", Text: "v1", BaseTimestamp: rev.Timestamp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.NoChange {
+		t.Errorf("Edit() with same text = %+v, want NoChange", result)
+	}
+
+	// Someone edits on the wiki, so an edit based on the old revision
+	// conflicts
+	server.SetPage("Guide", "edited on the wiki")
+	_, err = client.Edit(ctx, token, EditRequest{Title: "Guide", Text: "v2", BaseTimestamp: rev.Timestamp})
+	if !errors.Is(err, ErrEditConflict) {
+		t.Errorf("Edit() error = %v, want ErrEditConflict", err)
+	}
+	if got := server.Page("Guide").Text; got != "edited on the wiki" {
+		t.Errorf("page text = %q, want the wiki edit kept", got)
+	}
+
+	// A page created since it was found missing is not overwritten
+	_, err = client.Edit(ctx, token, EditRequest{Title: "Guide", Text: "v3", CreateOnly: true})
+	if !errors.Is(err, ErrEditConflict) {
+		t.Errorf("Edit() with CreateOnly error = %v, want ErrEditConflict", err)
+	}
+	if _, err := client.Edit(ctx, token, EditRequest{Title: "New", Text: "v1", CreateOnly: true}); err != nil {
+		t.Errorf("Edit() creating a page: %v", err)
+	}
+}
+
+func TestEditRequiresLogin(t *testing.T) {
+	server := mediawikitest.NewServer("Docs@publisher", "secret")
+	defer server.Close()
+	ctx := context.Background()
+
+	client := newTestClient(t, server)
+	token, err := client.CSRFToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Edit(ctx, token, EditRequest{Title: "Guide", Text: "v1"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "assertuserfailed" {
+		t.Errorf("Edit() error = %v, want assertuserfailed", err)
+	}
+	if server.Edits() != 0 {
+		t.Errorf("server saved %d edits, want none", server.Edits())
+	}
+}
+
+func TestLatestRevisionMissingPage(t *testing.T) {
+	server := mediawikitest.NewServer("Docs@publisher", "secret")
+	defer server.Close()
+
+	rev, err := newTestClient(t, server).LatestRevision(context.Background(), "Nowhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Exists {
+		t.Errorf("LatestRevision() = %+v, want missing page", rev)
+	}
+}
+
+func TestNewClientInvalidURL(t *testing.T) {
+	if _, err := NewClient("not a url", nil); err == nil {
+		t.Error("NewClient() error = nil, want invalid URL error")
+	}
+}
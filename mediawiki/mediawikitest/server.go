@@ -0,0 +1,226 @@
+// Package mediawikitest provides an in-memory stand-in for a wiki's api.php
+// for testing code that publishes pages
+package mediawikitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Page is a page stored by the test server
+type Page struct {
+	Text      string
+	RevID     int
+	Timestamp string
+	Minor     bool
+	Bot       bool
+	Summary   string
+}
+
+// Server is a minimal api.php supporting login and CSRF tokens, revision
+// queries and edits with basetimestamp and createonly conflict detection
+type Server struct {
+	*httptest.Server
+
+	Username string // Bot password user name accepted by action=login
+	Password string
+
+	mu       sync.Mutex
+	pages    map[string]*Page
+	sessions map[string]bool // session cookie -> logged in
+	revID    int
+	edits    int
+	clock    time.Time
+}
+
+const (
+	sessionCookie = "testwiki_session"
+	loginToken    = "login-token+\\"
+	csrfToken     = "csrf-token+\\"
+)
+
+// NewServer starts a test wiki accepting the given bot credentials
+func NewServer(username, password string) *Server {
+	s := &Server{
+		Username: username,
+		Password: password,
+		pages:    make(map[string]*Page),
+		sessions: make(map[string]bool),
+		clock:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// APIURL returns the address of api.php
+func (s *Server) APIURL() string {
+	return s.URL + "/w/api.php"
+}
+
+// SetPage stores a page as if someone had edited it on the wiki and
+// returns its revision timestamp
+func (s *Server) SetPage(title, text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(title, &Page{Text: text})
+}
+
+// Page returns a stored page, or nil if it does not exist
+func (s *Server) Page(title string) *Page {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if page, ok := s.pages[title]; ok {
+		copied := *page
+		return &copied
+	}
+	return nil
+}
+
+// Edits returns the number of edits saved through the API
+func (s *Server) Edits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.edits
+}
+
+// save stores a new revision one minute after the previous one
+func (s *Server) save(title string, page *Page) string {
+	s.revID++
+	s.clock = s.clock.Add(time.Minute)
+	page.RevID = s.revID
+	page.Timestamp = s.clock.Format(time.RFC3339)
+	s.pages[title] = page
+	return page.Timestamp
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/w/api.php" {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := ""
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		session = cookie.Value
+	}
+	if session == "" {
+		session = fmt.Sprintf("s%d", len(s.sessions)+1)
+		s.sessions[session] = false
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/"})
+	}
+
+	var resp interface{}
+	switch action := r.Form.Get("action"); action {
+	case "query":
+		resp = s.query(r, session)
+	case "login":
+		resp = s.login(r, session)
+	case "edit":
+		resp = s.edit(r, session)
+	default:
+		resp = apiError("badvalue", "Unrecognized value for parameter \"action\": "+action+".")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type object = map[string]interface{}
+
+func apiError(code, info string) object {
+	return object{"error": object{"code": code, "info": info}}
+}
+
+func (s *Server) query(r *http.Request, session string) object {
+	if r.Form.Get("meta") == "tokens" {
+		switch r.Form.Get("type") {
+		case "login":
+			return object{"query": object{"tokens": object{"logintoken": loginToken}}}
+		case "csrf", "":
+			token := "+\\"
+			if s.sessions[session] {
+				token = csrfToken
+			}
+			return object{"query": object{"tokens": object{"csrftoken": token}}}
+		}
+		return apiError("badvalue", "Unrecognized value for parameter \"type\".")
+	}
+
+	title := r.Form.Get("titles")
+	page, ok := s.pages[title]
+	if !ok {
+		return object{"query": object{"pages": []object{{"title": title, "missing": true}}}}
+	}
+	revisions := []object{{"revid": page.RevID, "timestamp": page.Timestamp}}
+	return object{"query": object{"pages": []object{{"title": title, "revisions": revisions}}}}
+}
+
+func (s *Server) login(r *http.Request, session string) object {
+	if r.Method != http.MethodPost {
+		return apiError("mustbeposted", "The \"login\" module requires a POST request.")
+	}
+	if r.Form.Get("lgtoken") != loginToken {
+		return object{"login": object{"result": "WrongToken"}}
+	}
+	if r.Form.Get("lgname") != s.Username || r.Form.Get("lgpassword") != s.Password {
+		return object{"login": object{"result": "Failed", "reason": "Incorrect username or password entered. Please try again."}}
+	}
+	s.sessions[session] = true
+	return object{"login": object{"result": "Success", "lgusername": s.Username}}
+}
+
+func (s *Server) edit(r *http.Request, session string) object {
+	if r.Method != http.MethodPost {
+		return apiError("mustbeposted", "The \"edit\" module requires a POST request.")
+	}
+	if r.Form.Get("assert") == "user" && !s.sessions[session] {
+		return apiError("assertuserfailed", "You are no longer logged in, so the action could not be completed.")
+	}
+	if r.Form.Get("token") != csrfToken {
+		return apiError("badtoken", "Invalid CSRF token.")
+	}
+
+	title := r.Form.Get("title")
+	text := r.Form.Get("text")
+	old, exists := s.pages[title]
+	if r.Form.Get("createonly") != "" && exists {
+		return apiError("articleexists", "The article you tried to create has been created already.")
+	}
+	if base := r.Form.Get("basetimestamp"); base != "" && exists && base != old.Timestamp {
+		return apiError("editconflict", "Edit conflict.")
+	}
+	if exists && old.Text == text {
+		return object{"edit": object{"result": "Success", "title": title, "pageid": 1, "nochange": true}}
+	}
+
+	oldRevID := 0
+	if exists {
+		oldRevID = old.RevID
+	}
+	s.edits++
+	timestamp := s.save(title, &Page{
+		Text:    text,
+		Summary: r.Form.Get("summary"),
+		Minor:   r.Form.Get("minor") != "",
+		Bot:     r.Form.Get("bot") != "",
+	})
+	return object{"edit": object{
+		"result":       "Success",
+		"title":        title,
+		"pageid":       1,
+		"oldrevid":     oldRevID,
+		"newrevid":     s.pages[title].RevID,
+		"newtimestamp": timestamp,
+	}}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+	flag "github.com/spf13/pflag"
+)
+
+// conversionOptions holds the flags shared by single-file and batch
+// conversion
+type conversionOptions struct {
+	withCSS     bool
+	concurrent  bool
+	linkNS      string
+	linkCase    string
+	linkSpaces  string
+	linkFlat    bool
+	filePrefix  string
+	fileFolders bool
+	tables      converter.TableConfig
+	tasks       converter.TaskConfig
+	callouts    converter.CalloutConfig
+	attribution bool
+	boldTerms   bool
+	frontMatter *converter.FrontMatterConfig
+	theme       string
+	strict      bool
+	disable     []string
+	enable      []string
+}
+
+// register defines the conversion flags on a flag set
+func (o *conversionOptions) register(flags *flag.FlagSet) {
+	o.frontMatter = converter.DefaultFrontMatterConfig()
+
+	flags.BoolVar(&o.withCSS, "with-css", false, "Include CSS styling in output")
+	flags.BoolVarP(&o.concurrent, "concurrent", "c", false, "Use concurrent processing for large files (>50KB)")
+	flags.StringVar(&o.linkNS, "link-namespace", "", "Namespace prefix for [[wikilink]] page titles")
+	flags.StringVar(&o.linkCase, "link-case", "", "Page title case for wikilinks: first, lower or title (default: as written)")
+	flags.StringVar(&o.linkSpaces, "link-spaces", "", "Write wikilink titles with 'spaces' or 'underscores' (default: as written)")
+	flags.BoolVar(&o.linkFlat, "link-strip-folders", false, "Drop vault folders from wikilink page titles")
+	flags.StringVar(&o.filePrefix, "file-prefix", "", "Prefix for wiki file names of images and embeds")
+	flags.BoolVar(&o.fileFolders, "file-keep-folders", false, "Keep folders in wiki file names (assets/a.png -> assets-a.png)")
+	flags.BoolVar(&o.tables.Sortable, "table-sortable", false, "Make tables sortable by column")
+	flags.BoolVar(&o.tables.Collapsible, "table-collapsible", false, "Give tables a show/hide toggle")
+	flags.BoolVar(&o.tables.Collapsed, "table-collapsed", false, "Start tables collapsed (implies --table-collapsible)")
+	flags.BoolVar(&o.tables.HeaderColumn, "table-header-column", false, "Write the first cell of each table row as a header cell")
+	flags.StringVar(&o.tables.Width, "table-width", "", "CSS width of tables, e.g. 100% (default: as wide as the content)")
+	flags.StringVar(&o.tasks.Template, "task-template", "", "Template for task list checkboxes, e.g. Checkbox for {{Checkbox}} and {{Checkbox|checked}}")
+	flags.BoolVar(&o.tasks.Summary, "task-summary", false, "Write a \"3/7 done\" line above each task list")
+	flags.StringVar(&o.callouts.Template, "callout-template", "", "Write callouts as calls to this template, e.g. Callout (see the callout-template command)")
+	flags.BoolVar(&o.attribution, "quote-attribution", false, "Write a closing \"— Author\" line of a block quote as a cite footer")
+	flags.BoolVar(&o.boldTerms, "bold-term-definitions", false, "Write \"**Term**: description\" paragraphs as definition lists")
+	flags.StringSliceVar(&o.frontMatter.CategoryKeys, "fm-categories", o.frontMatter.CategoryKeys, "Front matter keys whose values become [[Category:...]] links")
+	flags.StringVar(&o.frontMatter.TitleKey, "fm-title", o.frontMatter.TitleKey, "Front matter key for {{DISPLAYTITLE:}} (empty to skip)")
+	flags.StringVar(&o.frontMatter.Template, "fm-template", o.frontMatter.Template, "Infobox template for other front matter keys (empty to skip)")
+	flags.StringSliceVar(&o.frontMatter.TemplateKeys, "fm-template-keys", nil, "Front matter keys passed to the infobox, in order (default: all other keys)")
+	flags.StringSliceVar(&o.frontMatter.IgnoreKeys, "fm-ignore", o.frontMatter.IgnoreKeys, "Front matter keys never passed to the infobox")
+	flags.StringVar(&o.theme, "theme", converter.ThemeTieto, "Colors and CSS: 'tieto', 'plain' or a YAML/JSON theme file")
+	flags.BoolVar(&o.strict, "strict", false, "Exit with an error when the conversion reports warnings, not only errors")
+	flags.StringSliceVar(&o.disable, "disable-pass", nil, "Skip the named conversion pass (repeatable, comma-separated)")
+	flags.StringSliceVar(&o.enable, "enable-pass", nil, "Run the named conversion pass if it is off by default (repeatable, comma-separated)")
+}
+
+// config validates the flags and builds the converter configuration
+func (o *conversionOptions) config() (converter.Config, error) {
+	// Configure the pass pipeline
+	pipeline := converter.DefaultPipeline()
+	for _, name := range o.disable {
+		if err := pipeline.Disable(name); err != nil {
+			return converter.Config{}, fmt.Errorf("%w (see --list-passes)", err)
+		}
+	}
+	enable := o.enable
+	if o.boldTerms {
+		enable = append(enable, converter.PassBoldTermDefinitions)
+	}
+	if o.attribution {
+		enable = append(enable, converter.PassQuoteAttributions)
+	}
+	for _, name := range enable {
+		if err := pipeline.Enable(name); err != nil {
+			return converter.Config{}, fmt.Errorf("%w (see --list-passes)", err)
+		}
+	}
+
+	wikiLinks, err := wikiLinkConfig(o.linkNS, o.linkCase, o.linkSpaces, o.linkFlat)
+	if err != nil {
+		return converter.Config{}, err
+	}
+
+	theme, err := loadTheme(o.theme)
+	if err != nil {
+		return converter.Config{}, err
+	}
+
+	if err := o.tables.Validate(); err != nil {
+		return converter.Config{}, fmt.Errorf("--table-width: %w", err)
+	}
+
+	return converter.Config{
+		AddStyling: o.withCSS,
+		Concurrent: o.concurrent,
+		Pipeline:   pipeline,
+		WikiLinks:  wikiLinks,
+		Files:      converter.FileConfig{Prefix: o.filePrefix, KeepFolders: o.fileFolders},
+		Tables:     o.tables,
+		Tasks:      o.tasks,
+		Callouts:   o.callouts,
+
+		FrontMatter: o.frontMatter,
+		Theme:       theme,
+	}, nil
+}
+
+// loadTheme returns the built-in theme with the given name or loads a theme
+// file. An empty name means the default theme.
+func loadTheme(name string) (*converter.Theme, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if theme, ok := converter.BuiltinTheme(name); ok {
+		return theme, nil
+	}
+	theme, err := converter.LoadTheme(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("unknown --theme %q (want %s, %s or a theme file)", name, converter.ThemeTieto, converter.ThemePlain)
+	}
+	return theme, err
+}
+
+// wikiLinkConfig validates the wikilink flags
+func wikiLinkConfig(namespace, titleCase, spaces string, stripFolders bool) (converter.WikiLinkConfig, error) {
+	config := converter.WikiLinkConfig{
+		Namespace:    namespace,
+		Case:         converter.TitleCase(titleCase),
+		Spaces:       converter.SpaceMode(spaces),
+		StripFolders: stripFolders,
+	}
+	switch config.Case {
+	case converter.CaseKeep, converter.CaseFirstUpper, converter.CaseLower, converter.CaseTitle:
+	default:
+		return config, fmt.Errorf("invalid --link-case %q (want first, lower or title)", titleCase)
+	}
+	switch config.Spaces {
+	case converter.SpacesKeep, converter.SpacesSpaces, converter.SpacesUnderscores:
+	default:
+		return config, fmt.Errorf("invalid --link-spaces %q (want spaces or underscores)", spaces)
+	}
+	return config, nil
+}
+
+// printPasses lists the pipeline's passes with their stage and status
+func printPasses(pipeline *converter.Pipeline) {
+	for _, pass := range pipeline.Passes() {
+		stage := "text"
+		if _, ok := pass.(converter.TreePass); ok {
+			stage = "tree"
+		}
+		status := "enabled"
+		if !pipeline.Enabled(pass.Name()) {
+			status = "disabled"
+		}
+		fmt.Printf("%-24s %-5s %s\n", pass.Name(), stage, status)
+	}
+}
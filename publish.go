@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/mediawiki"
+	flag "github.com/spf13/pflag"
+)
+
+// publishPage is one converted file ready to be saved on the wiki
+type publishPage struct {
+	source      string
+	title       string
+	text        string
+	diagnostics []converter.Diagnostic
+}
+
+// preparePages converts the files and maps them to page titles. Without an
+// explicit title a page is named after its file, mapped like a wikilink.
+func preparePages(files []string, title string, config converter.Config) ([]publishPage, error) {
+	pages := make([]publishPage, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		pageTitle := title
+		if pageTitle == "" {
+			pageTitle = config.WikiLinks.PageTitle(filepath.Base(file))
+		}
+		result := converter.Convert(string(data), config)
+		pages = append(pages, publishPage{
+			source:      file,
+			title:       pageTitle,
+			text:        result.Text,
+			diagnostics: result.Diagnostics,
+		})
+	}
+	return pages, nil
+}
+
+// publishStateFile is where publish remembers the revisions it saved
+const publishStateFile = ".mdwiki-publish.json"
+
+// publishRecord is the revision a page was last published as
+type publishRecord struct {
+	RevID     int    `json:"revid"`
+	Timestamp string `json:"timestamp"`
+}
+
+// publishState holds the last published revision of each page, by api.php
+// URL and page title. Its timestamps are the base of the next publish, so
+// edits made on the wiki since then are never overwritten.
+type publishState map[string]map[string]publishRecord
+
+// loadPublishState reads a state file; a missing file is an empty state
+func loadPublishState(name string) (publishState, error) {
+	state := make(publishState)
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return state, nil
+}
+
+// save writes the state file as indented JSON
+func (s publishState) save(name string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0644)
+}
+
+// record returns the last published revision of a page
+func (s publishState) record(api, title string) (publishRecord, bool) {
+	rec, ok := s[api][title]
+	return rec, ok
+}
+
+// set stores the published revision of a page
+func (s publishState) set(api, title string, rec publishRecord) {
+	if s[api] == nil {
+		s[api] = make(map[string]publishRecord)
+	}
+	s[api][title] = rec
+}
+
+// runPublish implements the publish subcommand, which converts Markdown
+// files and saves them through the wiki's Action API. It returns the
+// process exit code.
+func runPublish(args []string) int {
+	flags := flag.NewFlagSet("publish", flag.ContinueOnError)
+	var (
+		apiURL        string
+		username      string
+		passwordEnv   string
+		title         string
+		summary       string
+		minor         bool
+		bot           bool
+		dryRun        bool
+		baseTimestamp string
+		stateFile     string
+		options       conversionOptions
+	)
+	flags.StringVar(&apiURL, "api", os.Getenv("MW_API_URL"), "URL of the wiki's api.php (default: $MW_API_URL)")
+	flags.StringVar(&username, "user", os.Getenv("MW_BOT_USER"), "Bot password user name, e.g. Name@Bot (default: $MW_BOT_USER)")
+	flags.StringVar(&passwordEnv, "password-env", "MW_BOT_PASSWORD", "Environment variable holding the bot password")
+	flags.StringVar(&title, "title", "", "Page title (default: the file name, mapped by the --link-* options)")
+	flags.StringVar(&summary, "summary", "Published with md-to-mediawiki-plus", "Edit summary")
+	flags.BoolVar(&minor, "minor", false, "Mark the edits as minor")
+	flags.BoolVar(&bot, "bot", false, "Mark the edits as bot edits (needs the bot right)")
+	flags.BoolVar(&dryRun, "dry-run", false, "Convert and show what would be published without contacting the wiki")
+	flags.StringVar(&baseTimestamp, "base-timestamp", "", "Refuse to save if the page changed after this revision timestamp (default: the last publish recorded in --state)")
+	flags.StringVar(&stateFile, "state", publishStateFile, "File recording the revision each page was last published as")
+	options.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	files := flags.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: publish needs at least one Markdown file")
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Usage: md-to-mediawiki-go publish [options] <file.md>...")
+		flags.PrintDefaults()
+		return 2
+	}
+	if len(files) > 1 && (title != "" || baseTimestamp != "") {
+		fmt.Fprintln(os.Stderr, "Error: --title and --base-timestamp can only be used when publishing one file")
+		return 2
+	}
+
+	config, err := options.config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	pages, err := preparePages(files, title, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	var diagnostics []converter.Diagnostic
+	for _, page := range pages {
+		printDiagnostics(os.Stderr, page.source, page.diagnostics)
+		diagnostics = append(diagnostics, page.diagnostics...)
+	}
+	if failure := diagnosticFailure(diagnostics, options.strict); failure != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s; nothing published\n", failure)
+		return 1
+	}
+
+	if dryRun {
+		for _, page := range pages {
+			fmt.Printf("Would publish '%s' -> '%s' (%d bytes, summary %q)\n", page.source, page.title, len(page.text), summary)
+		}
+		return 0
+	}
+
+	password := os.Getenv(passwordEnv)
+	if apiURL == "" || username == "" || password == "" {
+		fmt.Fprintf(os.Stderr, "Error: publish needs --api, --user and the bot password in $%s\n", passwordEnv)
+		return 1
+	}
+
+	state, err := loadPublishState(stateFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	client, err := mediawiki.NewClient(apiURL, nil)
+	if err == nil {
+		err = client.Login(ctx, username, password)
+	}
+	var token string
+	if err == nil {
+		token, err = client.CSRFToken(ctx)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	failed := 0
+	for _, page := range pages {
+		edit := mediawiki.EditRequest{
+			Title:         page.title,
+			Text:          page.text,
+			Summary:       summary,
+			Minor:         minor,
+			Bot:           bot,
+			BaseTimestamp: baseTimestamp,
+		}
+		if rec, ok := state.record(apiURL, page.title); ok && edit.BaseTimestamp == "" {
+			edit.BaseTimestamp = rec.Timestamp
+		}
+		if edit.BaseTimestamp == "" {
+			// Without a base only a missing page can be protected
+			rev, err := client.LatestRevision(ctx, page.title)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %s: %v\n", page.title, err)
+				failed++
+				continue
+			}
+			if rev.Exists {
+				fmt.Fprintf(os.Stderr, "⚠️  '%s' was never published from here; replacing its current revision (use --base-timestamp to guard it)\n", page.title)
+			} else {
+				edit.CreateOnly = true
+			}
+		}
+
+		result, err := client.Edit(ctx, token, edit)
+		switch {
+		case errors.Is(err, mediawiki.ErrEditConflict) && edit.CreateOnly:
+			fmt.Fprintf(os.Stderr, "❌ '%s' was created on the wiki while publishing; not overwritten\n", page.title)
+			failed++
+		case errors.Is(err, mediawiki.ErrEditConflict):
+			fmt.Fprintf(os.Stderr, "❌ '%s' was edited on the wiki after %s; not overwritten\n", page.title, edit.BaseTimestamp)
+			failed++
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", page.title, err)
+			failed++
+		case result.NoChange:
+			// The wiki holds this text, so its revision is a safe base
+			if rev, err := client.LatestRevision(ctx, page.title); err == nil && rev.Exists {
+				state.set(apiURL, page.title, publishRecord{RevID: rev.RevID, Timestamp: rev.Timestamp})
+			}
+			fmt.Printf("✅ '%s' is already up to date\n", page.title)
+		default:
+			state.set(apiURL, page.title, publishRecord{RevID: result.NewRevID, Timestamp: result.NewTimestamp})
+			fmt.Printf("✅ Published '%s' -> '%s' (revision %d, %s)\n", page.source, page.title, result.NewRevID, result.NewTimestamp)
+		}
+	}
+	if err := state.save(stateFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/mediawiki/mediawikitest"
+)
+
+func TestRunPublish(t *testing.T) {
+	server := mediawikitest.NewServer("Docs@publisher", "secret")
+	defer server.Close()
+	t.Setenv("MW_TEST_PASSWORD", "secret")
+
+	dir := t.TempDir()
+	note := filepath.Join(dir, "release notes.md")
+	stateFile := filepath.Join(dir, "state.json")
+	writeTestFile(t, note, "# Release\n")
+	login := []string{"--api", server.APIURL(), "--user", "Docs@publisher", "--password-env", "MW_TEST_PASSWORD", "--state", stateFile}
+
+	// Dry runs never contact the wiki
+	if code := runPublish(append([]string{"--dry-run", "--api", "http://127.0.0.1:1/api.php"}, note)); code != 0 {
+		t.Fatalf("dry run exit code = %d, want 0", code)
+	}
+
+	// Titles are mapped like wikilinks, and the saved revision is recorded
+	args := append(append([]string{}, login...), "--link-namespace", "Docs", "--link-case", "first", "--minor", note)
+	if code := runPublish(args); code != 0 {
+		t.Fatalf("publish exit code = %d, want 0", code)
+	}
+	page := server.Page("Docs:Release notes")
+	if page == nil || !strings.Contains(page.Text, "Release</span>=") || !page.Minor {
+		t.Fatalf("published page = %+v", page)
+	}
+	state, err := loadPublishState(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, ok := state.record(server.APIURL(), "Docs:Release notes"); !ok || rec.Timestamp != page.Timestamp || rec.RevID != page.RevID {
+		t.Errorf("recorded revision = %+v, want %d at %s", rec, page.RevID, page.Timestamp)
+	}
+	base := page.Timestamp
+
+	// The next publish is based on the recorded revision
+	writeTestFile(t, note, "# Release 2\n")
+	args = append(append([]string{}, login...), "--title", "Docs:Release notes", note)
+	if code := runPublish(args); code != 0 {
+		t.Fatalf("second publish exit code = %d, want 0", code)
+	}
+
+	// An edit made on the wiki since the last publish is not overwritten
+	server.SetPage("Docs:Release notes", "edited on the wiki")
+	writeTestFile(t, note, "# Release 3\n")
+	if code := runPublish(args); code != 1 {
+		t.Fatalf("conflicting publish exit code = %d, want 1", code)
+	}
+	if got := server.Page("Docs:Release notes").Text; got != "edited on the wiki" {
+		t.Errorf("page text = %q, want the wiki edit kept", got)
+	}
+
+	// Nor is it with an explicit base timestamp
+	args = append(append([]string{}, login...), "--title", "Docs:Release notes", "--base-timestamp", base, note)
+	if code := runPublish(args); code != 1 {
+		t.Fatalf("conflicting publish with --base-timestamp exit code = %d, want 1", code)
+	}
+	if server.Edits() != 2 {
+		t.Errorf("server saved %d edits, want 2", server.Edits())
+	}
+}
+
+func TestPublishStateMissingFile(t *testing.T) {
+	state, err := loadPublishState(filepath.Join(t.TempDir(), "none.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.record("https://wiki.example.com/w/api.php", "Page"); ok {
+		t.Error("record() found a page in an empty state")
+	}
+}
+
+func TestRunPublishBadPassword(t *testing.T) {
+	server := mediawikitest.NewServer("Docs@publisher", "secret")
+	defer server.Close()
+	t.Setenv("MW_TEST_PASSWORD", "wrong")
+
+	note := filepath.Join(t.TempDir(), "a.md")
+	writeTestFile(t, note, "text\n")
+	code := runPublish([]string{"--api", server.APIURL(), "--user", "Docs@publisher", "--password-env", "MW_TEST_PASSWORD", "--state", filepath.Join(t.TempDir(), "state.json"), note})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if server.Edits() != 0 {
+		t.Errorf("server saved %d edits, want none", server.Edits())
+	}
+}
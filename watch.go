@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/olgasafonova/md-to-mediawiki-go/md-to-mediawiki-plus/converter"
+)
+
+// watchDebounce is how long files must stay unchanged before a rebuild, so
+// an editor saving several times in a row triggers one conversion
+const watchDebounce = 300 * time.Millisecond
+
+// fileStamp identifies one version of a file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// changedFiles lists the files that are new or modified in cur. Deleted
+// files are not reported since there is nothing to rebuild.
+func changedFiles(prev, cur map[string]fileStamp) []string {
+	var changed []string
+	for name, stamp := range cur {
+		if old, ok := prev[name]; !ok || old != stamp {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// watchFiles polls scan on every tick and calls rebuild with the files
+// that changed once they have been quiet for the debounce time, measured
+// with the tick times. Scan errors are logged and retried on the next poll.
+// It returns when stop is closed.
+func watchFiles(scan func() (map[string]fileStamp, error), rebuild func(changed []string), ticks <-chan time.Time, debounce time.Duration, stop <-chan struct{}) {
+	prev, err := scan()
+	if err != nil {
+		logStatus("❌ %v", err)
+	}
+
+	pending := make(map[string]bool)
+	var lastChange time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticks:
+			cur, err := scan()
+			if err != nil {
+				logStatus("❌ %v", err)
+				continue
+			}
+			if changed := changedFiles(prev, cur); len(changed) > 0 {
+				for _, name := range changed {
+					pending[name] = true
+				}
+				lastChange = now
+			}
+			prev = cur
+
+			if len(pending) > 0 && now.Sub(lastChange) >= debounce {
+				names := make([]string, 0, len(pending))
+				for name := range pending {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				pending = make(map[string]bool)
+				rebuild(names)
+			}
+		}
+	}
+}
+
+// stampFile returns the current stamp of a file. Missing files, e.g. while
+// an editor replaces a file on save, are reported as not ok.
+func stampFile(name string) (fileStamp, bool) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}, false
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, true
+}
+
+// watchFile rebuilds a single output file whenever its input changes
+func watchFile(input, output string, config converter.Config, interval time.Duration, strict bool) {
+	rebuild := func([]string) {
+		start := time.Now()
+		diagnostics, err := convertPath(input, output, config)
+		printDiagnostics(os.Stderr, input, diagnostics)
+		if err != nil {
+			logStatus("❌ %s: %v", input, err)
+			return
+		}
+		if failure := diagnosticFailure(diagnostics, strict); failure != "" {
+			logStatus("❌ %s: %s", input, failure)
+			return
+		}
+		logStatus("✅ Rebuilt '%s' -> '%s' (%s)", input, output, time.Since(start).Round(time.Microsecond))
+	}
+	scan := func() (map[string]fileStamp, error) {
+		stamps := make(map[string]fileStamp)
+		if stamp, ok := stampFile(input); ok {
+			stamps[input] = stamp
+		}
+		return stamps, nil
+	}
+
+	rebuild(nil)
+	logStatus("👀 Watching '%s' (Ctrl+C to stop)", input)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	watchFiles(scan, rebuild, ticker.C, watchDebounce, interrupted())
+}
+
+// watchDir rebuilds the outputs of changed files under a directory,
+// picking up new files as they are created
+func watchDir(inputDir, outputDir string, matcher *pathMatcher, config converter.Config, workers int, interval time.Duration, strict bool) {
+	rebuild := func(changed []string) {
+		start := time.Now()
+		reports := convertFiles(inputDir, outputDir, changed, config, workers)
+		failed := reportResults(inputDir, reports, strict, logStatus)
+		logStatus("✅ Rebuilt %d of %d changed files (%s)", len(changed)-failed, len(changed), time.Since(start).Round(time.Microsecond))
+	}
+	scan := func() (map[string]fileStamp, error) {
+		files, _, err := collectFiles(inputDir, matcher)
+		if err != nil {
+			return nil, err
+		}
+		stamps := make(map[string]fileStamp, len(files))
+		for _, rel := range files {
+			if stamp, ok := stampFile(filepath.Join(inputDir, filepath.FromSlash(rel))); ok {
+				stamps[rel] = stamp
+			}
+		}
+		return stamps, nil
+	}
+
+	logStatus("👀 Watching '%s' (Ctrl+C to stop)", inputDir)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	watchFiles(scan, rebuild, ticker.C, watchDebounce, interrupted())
+}
+
+// interrupted returns a channel closed on Ctrl+C
+func interrupted() <-chan struct{} {
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		close(stop)
+	}()
+	return stop
+}
+
+// logStatus prints a timestamped one-line watch status
+func logStatus(format string, args ...interface{}) {
+	fmt.Printf("%s %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+}
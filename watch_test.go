@@ -0,0 +1,87 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChangedFiles(t *testing.T) {
+	now := time.Now()
+	prev := map[string]fileStamp{
+		"a.md": {modTime: now, size: 1},
+		"b.md": {modTime: now, size: 1},
+		"c.md": {modTime: now, size: 1},
+	}
+	cur := map[string]fileStamp{
+		"a.md": {modTime: now, size: 1},
+		"b.md": {modTime: now.Add(time.Second), size: 1},
+		"d.md": {modTime: now, size: 1},
+	}
+
+	got := changedFiles(prev, cur)
+	expected := []string{"b.md", "d.md"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("changedFiles() = %v, want %v", got, expected)
+	}
+}
+
+func TestWatchFilesDebounces(t *testing.T) {
+	var mu sync.Mutex
+	version := 0
+	scan := func() (map[string]fileStamp, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]fileStamp{"a.md": {size: int64(version)}}, nil
+	}
+	save := func() {
+		mu.Lock()
+		version++
+		mu.Unlock()
+	}
+
+	// The ticks are sent by hand, so the debounce only depends on their
+	// times. A send returns once the previous tick has been handled.
+	rebuilds := make(chan []string, 10)
+	ticks := make(chan time.Time)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		watchFiles(scan, func(changed []string) { rebuilds <- changed }, ticks, 50*time.Millisecond, stop)
+		close(done)
+	}()
+	start := time.Now()
+	tick := func(ms int) {
+		ticks <- start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	expectRebuilds := func(n int) {
+		t.Helper()
+		if got := len(rebuilds); got != n {
+			t.Fatalf("got %d rebuilds, want %d", got, n)
+		}
+	}
+
+	// A burst of saves leads to a single rebuild once it has been quiet
+	tick(0)
+	for _, ms := range []int{10, 20, 30} {
+		save()
+		tick(ms)
+	}
+	tick(79)
+	tick(79)
+	expectRebuilds(0)
+	tick(80)
+	tick(90)
+	expectRebuilds(1)
+	if changed := <-rebuilds; !reflect.DeepEqual(changed, []string{"a.md"}) {
+		t.Errorf("rebuild changed = %v, want [a.md]", changed)
+	}
+
+	// Nothing more happens without further saves
+	tick(500)
+	tick(1000)
+	close(stop)
+	<-done
+	expectRebuilds(0)
+}